@@ -0,0 +1,89 @@
+package bpool
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+type countingAllocator struct {
+	Allocator
+	gets, puts int
+}
+
+func (ca *countingAllocator) Get(size int) *Bytes {
+	ca.gets++
+	b := ca.Allocator.Get(size)
+	b.SetAllocator(ca)
+	return b
+}
+
+func (ca *countingAllocator) Put(bytes *Bytes) {
+	ca.puts++
+	ca.Allocator.Put(bytes)
+}
+
+func TestSetDefault(t *testing.T) {
+	ca := &countingAllocator{Allocator: New()}
+	prev := SetDefault(ca)
+	defer SetDefault(prev)
+	assert.Eq(t, Allocator(ca), Default())
+
+	pb := Get(16)
+	pb.WriteString(strings.Repeat("x", 100))
+	pb.Grow(1000)
+	_, _ = pb.Write(make([]byte, 2000))
+	pb.Release()
+	assert.Eq(t, 4, ca.gets)
+	assert.Eq(t, 4, ca.puts)
+
+	// A Bytes not obtained from an Allocator grows through the default one.
+	b := &Bytes{}
+	b.WriteString("abc")
+	assert.Eq(t, 5, ca.gets)
+	assert.Eq(t, 5, ca.puts)
+	Put(b)
+	assert.Eq(t, 6, ca.puts)
+
+	SetDefault(nil)
+	assert.Eq(t, Allocator(defaultPool), Default())
+}
+
+func TestReleaseToOwningPool(t *testing.T) {
+	bp := New(WithStats())
+	pb := bp.Get(100)
+	assert.Eq(t, Allocator(bp), pb.Allocator())
+	pb.WriteString(strings.Repeat("x", 1000))
+	pb.Release()
+	s := bp.Stats()
+	var gets, puts uint64
+	for _, c := range s.Classes {
+		gets += c.Hits + c.Misses
+		puts += c.Puts
+	}
+	assert.Eq(t, uint64(2), gets)
+	assert.Eq(t, uint64(2), puts)
+}
+
+func TestGetResetsAllocator(t *testing.T) {
+	for _, bp := range []*BytesPool{
+		New(),
+		New(WithStats()),
+		New(WithRetention(Retention{MaxBuffers: 4})),
+	} {
+		ca := &countingAllocator{Allocator: bp}
+		pb := ca.Get(100)
+		assert.Eq(t, Allocator(ca), pb.Allocator())
+		pb.Release()
+		assert.Eq(t, 1, ca.puts)
+
+		// The recycled Bytes belongs to bp again, whoever released it last.
+		for i := 0; i < 10; i++ {
+			pb = bp.Get(100)
+			assert.Eq(t, Allocator(bp), pb.Allocator())
+			pb.Release()
+		}
+		assert.Eq(t, 1, ca.puts)
+	}
+}
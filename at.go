@@ -0,0 +1,127 @@
+package bpool
+
+import (
+	"errors"
+	"io"
+	"math"
+)
+
+var errNegativeOffset = errors.New("bpool: negative offset")
+var errInvalidWhence = errors.New("bpool.Reader.Seek: invalid whence")
+var errNegativePosition = errors.New("bpool.Reader.Seek: negative position")
+
+// ReadAt implements io.ReaderAt. off is an offset into ByteBuffer.B, counted
+// from its start whether or not those bytes have been read, and the read
+// offset is left unchanged. Note that the other write methods may drop the
+// bytes already read when they grow the buffer, as bytes.Buffer does, which
+// shifts these offsets.
+func (b *Bytes) ReadAt(p []byte, off int64) (n int, err error) {
+	if off < 0 {
+		return 0, errNegativeOffset
+	}
+	if off >= int64(len(b.B)) {
+		return 0, io.EOF
+	}
+	n = copy(p, b.B[off:])
+	if n < len(p) {
+		err = io.EOF
+	}
+	return
+}
+
+// WriteAt implements io.WriterAt. off is an offset into ByteBuffer.B like for
+// ReadAt. Writing past the end extends the buffer, growing it through its
+// Allocator, and fills any gap between the old end and off with zeros.
+func (b *Bytes) WriteAt(p []byte, off int64) (n int, err error) {
+	b.checkLive("WriteAt")
+	if off < 0 {
+		return 0, errNegativeOffset
+	}
+	if off > int64(math.MaxInt-len(p)) {
+		// off+len(p) does not fit an int, so it is past any possible buffer.
+		return 0, ErrTooLarge
+	}
+	b.lastRead = opInvalid
+	if end := int(off) + len(p); end > len(b.B) {
+		oldLen := len(b.B)
+		if b.tooLarge(end - oldLen) {
+			return 0, ErrTooLarge
+		}
+		if end > cap(b.B) {
+			// Unlike Grow, keep the bytes already read: off counts them.
+			alloc := b.Allocator()
+			b2 := alloc.Get(end)
+			b2.B = append(b2.B, b.B...)
+			b.B, b2.B = b2.B, b.B
+			alloc.Put(b2)
+		}
+		b.B = b.B[:end]
+		if int(off) > oldLen {
+			clear(b.B[oldLen:off])
+		}
+	}
+	return copy(b.B[off:], p), nil
+}
+
+// Reader is a read-only, seekable view of the content of a Bytes, see
+// Bytes.NewReader.
+type Reader struct {
+	b *Bytes
+	i int64
+}
+
+// NewReader returns an io.ReadSeeker and io.ReaderAt over ByteBuffer.B,
+// starting at its beginning. The Reader has its own position and does not
+// consume the bytes it reads; it sees later writes to b, and must not be
+// used after b is released.
+func (b *Bytes) NewReader() *Reader {
+	return &Reader{b: b}
+}
+
+// Len returns the number of bytes of the unread portion of the view.
+func (r *Reader) Len() int {
+	if r.i >= int64(len(r.b.B)) {
+		return 0
+	}
+	return len(r.b.B) - int(r.i)
+}
+
+// Size returns the length of the underlying Bytes content.
+func (r *Reader) Size() int64 {
+	return int64(len(r.b.B))
+}
+
+// Read implements io.Reader.
+func (r *Reader) Read(p []byte) (n int, err error) {
+	if r.i >= int64(len(r.b.B)) {
+		return 0, io.EOF
+	}
+	n = copy(p, r.b.B[r.i:])
+	r.i += int64(n)
+	return
+}
+
+// ReadAt implements io.ReaderAt.
+func (r *Reader) ReadAt(p []byte, off int64) (n int, err error) {
+	return r.b.ReadAt(p, off)
+}
+
+// Seek implements io.Seeker.
+func (r *Reader) Seek(offset int64, whence int) (int64, error) {
+	var abs int64
+	switch whence {
+	case io.SeekStart:
+		abs = offset
+	case io.SeekCurrent:
+		abs = r.i + offset
+	case io.SeekEnd:
+		abs = int64(len(r.b.B)) + offset
+	default:
+		return 0, errInvalidWhence
+	}
+	if abs < 0 {
+		return 0, errNegativePosition
+	}
+	r.i = abs
+	return abs, nil
+}
@@ -0,0 +1,83 @@
+package bpool
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestBytes_WriteAtBackpatch(t *testing.T) {
+	pb := Get(4)
+	_, _ = pb.Write(make([]byte, 4))
+	pb.WriteString("body of the message")
+	var hdr [4]byte
+	binary.BigEndian.PutUint32(hdr[:], uint32(pb.Len()-4))
+	n, err := pb.WriteAt(hdr[:], 0)
+	assert.NoErr(t, err)
+	assert.Eq(t, 4, n)
+	assert.Eq(t, "\x00\x00\x00\x13body of the message", pb.String())
+
+	p := make([]byte, 4)
+	n, err = pb.ReadAt(p, 4)
+	assert.NoErr(t, err)
+	assert.Eq(t, "body", string(p[:n]))
+	n, err = pb.ReadAt(p, int64(pb.Len()-2))
+	assert.Eq(t, io.EOF, err)
+	assert.Eq(t, "ge", string(p[:n]))
+	_, err = pb.ReadAt(p, -1)
+	assert.Err(t, err)
+	pb.Release()
+}
+
+func TestBytes_WriteAtPastEnd(t *testing.T) {
+	pb := Get(8)
+	pb.WriteString("garbage!")
+	pb.Reset()
+	pb.WriteString("ab")
+	_, err := pb.WriteAt([]byte("xyz"), 100)
+	assert.NoErr(t, err)
+	assert.Eq(t, 103, pb.Len())
+	for _, c := range pb.B[2:100] {
+		if c != 0 {
+			t.Fatalf("gap not zeroed: %q", pb.B[:100])
+		}
+	}
+	assert.Eq(t, "xyz", string(pb.B[100:]))
+	_, err = pb.WriteAt([]byte("x"), -1)
+	assert.Err(t, err)
+	_, err = pb.WriteAt([]byte("xy"), math.MaxInt64-1)
+	assert.Equal(t, ErrTooLarge, err)
+	assert.Eq(t, 103, pb.Len())
+	pb.Release()
+}
+
+func TestReader(t *testing.T) {
+	pb := Get(16)
+	pb.WriteString("0123456789")
+	r := pb.NewReader()
+	var _ io.ReadSeeker = r
+	var _ io.ReaderAt = r
+	assert.Eq(t, int64(10), r.Size())
+	p := make([]byte, 3)
+	n, _ := r.Read(p)
+	assert.Eq(t, "012", string(p[:n]))
+	pos, err := r.Seek(-2, io.SeekEnd)
+	assert.NoErr(t, err)
+	assert.Eq(t, int64(8), pos)
+	all, err := io.ReadAll(r)
+	assert.NoErr(t, err)
+	assert.Eq(t, "89", string(all))
+	pos, _ = r.Seek(-5, io.SeekCurrent)
+	assert.Eq(t, int64(5), pos)
+	assert.Eq(t, 5, r.Len())
+	_, err = r.Seek(-1, io.SeekStart)
+	assert.Err(t, err)
+	_, err = r.Seek(0, 7)
+	assert.Err(t, err)
+	// The view does not consume the Bytes.
+	assert.Eq(t, "0123456789", pb.String())
+	pb.Release()
+}
@@ -0,0 +1,79 @@
+package bpool
+
+import "io"
+
+// Batch collects Bytes to be written together, such as the header, body and
+// trailer of a response, so that WriteTo sends them with a single vectored
+// write where the writer supports it, see writeBuffers. A Batch owns the
+// Bytes added to it and releases each one once it has been written.
+type Batch struct {
+	bufs []*Bytes
+	vec  [][]byte // reused by WriteTo
+}
+
+// Add appends b to the batch, which takes over its ownership.
+func (bt *Batch) Add(b *Bytes) {
+	bt.bufs = append(bt.bufs, b)
+}
+
+// Len returns the number of unwritten bytes in the batch.
+func (bt *Batch) Len() (n int) {
+	for _, b := range bt.bufs {
+		n += b.Len()
+	}
+	return
+}
+
+// WriteTo implements io.WriterTo. Like Bytes.DrainTo it consumes the written
+// bytes: every Bytes written in full is released, and one written in part
+// keeps its remainder at the front of the batch, so that WriteTo can be
+// called again after a partial write. A short write without an error is
+// reported as io.ErrShortWrite.
+func (bt *Batch) WriteTo(w io.Writer) (n int64, err error) {
+	bt.vec = bt.vec[:0]
+	var total int64
+	for _, b := range bt.bufs {
+		if p := b.Bytes(); len(p) > 0 {
+			bt.vec = append(bt.vec, p)
+			total += int64(len(p))
+		}
+	}
+	n, err = writeBuffers(w, bt.vec)
+	clear(bt.vec)
+	if n > total {
+		panic("bpool.Batch.WriteTo: invalid Write count")
+	}
+	bt.consume(n)
+	if err == nil && n != total {
+		err = io.ErrShortWrite
+	}
+	return
+}
+
+// consume discards the first n bytes and releases the Bytes emptied by it.
+func (bt *Batch) consume(n int64) {
+	done := 0
+	for _, b := range bt.bufs {
+		m := int64(b.Len())
+		if m > n {
+			b.off += int(n)
+			b.lastRead = opInvalid
+			break
+		}
+		n -= m
+		b.Release()
+		done++
+	}
+	k := copy(bt.bufs, bt.bufs[done:])
+	clear(bt.bufs[k:])
+	bt.bufs = bt.bufs[:k]
+}
+
+// Release releases the Bytes that are still in the batch and empties it.
+func (bt *Batch) Release() {
+	for _, b := range bt.bufs {
+		b.Release()
+	}
+	clear(bt.bufs)
+	bt.bufs = bt.bufs[:0]
+}
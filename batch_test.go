@@ -0,0 +1,110 @@
+package bpool
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func newBatch(ca *countingAllocator, parts ...string) *Batch {
+	bt := &Batch{}
+	for _, p := range parts {
+		b := ca.Get(len(p))
+		b.WriteString(p)
+		bt.Add(b)
+	}
+	return bt
+}
+
+func TestBatch_WriteTo(t *testing.T) {
+	ca := &countingAllocator{Allocator: New()}
+	bt := newBatch(ca, "header|", "", "body|", "trailer")
+	assert.Eq(t, 19, bt.Len())
+	var out bytes.Buffer
+	n, err := bt.WriteTo(&out)
+	assert.NoErr(t, err)
+	assert.Eq(t, int64(19), n)
+	assert.Eq(t, "header|body|trailer", out.String())
+	assert.Eq(t, 0, bt.Len())
+	assert.Eq(t, 4, ca.puts)
+}
+
+func TestBatch_WriteToPartial(t *testing.T) {
+	ca := &countingAllocator{Allocator: New()}
+	bt := newBatch(ca, "header|", "body|", "trailer")
+	w := &shortWriter{limit: 9}
+	n, err := bt.WriteTo(w)
+	assert.Err(t, err)
+	assert.Eq(t, int64(9), n)
+	assert.Eq(t, 1, ca.puts)
+	assert.Eq(t, 10, bt.Len())
+
+	var out bytes.Buffer
+	n, err = bt.WriteTo(&out)
+	assert.NoErr(t, err)
+	assert.Eq(t, int64(10), n)
+	assert.Eq(t, "dy|trailer", out.String())
+	assert.Eq(t, 3, ca.puts)
+}
+
+type quietShortWriter struct{ bytes.Buffer }
+
+func (w *quietShortWriter) Write(p []byte) (int, error) {
+	return w.Buffer.Write(p[:len(p)/2])
+}
+
+func TestBatch_ShortWrite(t *testing.T) {
+	ca := &countingAllocator{Allocator: New()}
+	bt := newBatch(ca, "abcd", "efgh")
+	defer bt.Release()
+	w := &quietShortWriter{}
+	n, err := bt.WriteTo(w)
+	assert.Equal(t, io.ErrShortWrite, err)
+	assert.Eq(t, int64(2), n)
+	assert.Eq(t, "ab", w.String())
+	assert.Eq(t, 6, bt.Len())
+
+	// A retry picks up where the short write stopped.
+	n, err = bt.WriteTo(w)
+	assert.Equal(t, io.ErrShortWrite, err)
+	assert.Eq(t, int64(1), n)
+	assert.Eq(t, "abc", w.String())
+	var out bytes.Buffer
+	n, err = bt.WriteTo(&out)
+	assert.NoErr(t, err)
+	assert.Eq(t, int64(5), n)
+	assert.Eq(t, "defgh", out.String())
+	assert.Eq(t, 0, bt.Len())
+	assert.Eq(t, 2, ca.puts)
+}
+
+func TestBatch_Conn(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoErr(t, err)
+	defer ln.Close()
+	done := make(chan []byte)
+	go func() {
+		c, err := ln.Accept()
+		if err != nil {
+			done <- nil
+			return
+		}
+		p, _ := io.ReadAll(c)
+		c.Close()
+		done <- p
+	}()
+	c, err := net.Dial("tcp", ln.Addr().String())
+	assert.NoErr(t, err)
+	ca := &countingAllocator{Allocator: New()}
+	body := string(bytes.Repeat([]byte("x"), 100000))
+	bt := newBatch(ca, "header|", body, "|trailer")
+	n, err := bt.WriteTo(c)
+	assert.NoErr(t, err)
+	assert.Eq(t, int64(len(body)+15), n)
+	c.Close()
+	assert.Eq(t, "header|"+body+"|trailer", string(<-done))
+	assert.Eq(t, 3, ca.puts)
+}
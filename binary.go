@@ -0,0 +1,173 @@
+package bpool
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// The binary appenders encode fixed-size integers and varints into
+// ByteBuffer.B through Grow, so that the buffer keeps coming from the pool;
+// encoding/binary's own Append functions reallocate with the built-in append.
+// The size limit applies as described in format.go.
+//
+// The matching Read methods consume from the unread portion of the buffer.
+// They return io.EOF if the buffer is empty and io.ErrUnexpectedEOF if it
+// holds only part of a value, in which case nothing is consumed.
+
+// ErrVarintOverflow is returned by ReadUvarint and ReadVarint for a varint
+// that does not fit in 64 bits. The bytes of the varint are not consumed.
+var ErrVarintOverflow = errors.New("bpool.Bytes: varint overflows a 64-bit integer")
+
+// AppendUint16BE appends v in big-endian order.
+func (b *Bytes) AppendUint16BE(v uint16) {
+	b.mustGrow(2)
+	b.B = binary.BigEndian.AppendUint16(b.B, v)
+}
+
+// AppendUint32BE appends v in big-endian order.
+func (b *Bytes) AppendUint32BE(v uint32) {
+	b.mustGrow(4)
+	b.B = binary.BigEndian.AppendUint32(b.B, v)
+}
+
+// AppendUint64BE appends v in big-endian order.
+func (b *Bytes) AppendUint64BE(v uint64) {
+	b.mustGrow(8)
+	b.B = binary.BigEndian.AppendUint64(b.B, v)
+}
+
+// AppendUint16LE appends v in little-endian order.
+func (b *Bytes) AppendUint16LE(v uint16) {
+	b.mustGrow(2)
+	b.B = binary.LittleEndian.AppendUint16(b.B, v)
+}
+
+// AppendUint32LE appends v in little-endian order.
+func (b *Bytes) AppendUint32LE(v uint32) {
+	b.mustGrow(4)
+	b.B = binary.LittleEndian.AppendUint32(b.B, v)
+}
+
+// AppendUint64LE appends v in little-endian order.
+func (b *Bytes) AppendUint64LE(v uint64) {
+	b.mustGrow(8)
+	b.B = binary.LittleEndian.AppendUint64(b.B, v)
+}
+
+// AppendUvarint appends the varint encoding of v, as binary.AppendUvarint
+// does.
+func (b *Bytes) AppendUvarint(v uint64) {
+	var scratch [binary.MaxVarintLen64]byte
+	b.mustWrite(scratch[:binary.PutUvarint(scratch[:], v)])
+}
+
+// AppendVarint appends the zig-zag varint encoding of v, as
+// binary.AppendVarint does.
+func (b *Bytes) AppendVarint(v int64) {
+	var scratch [binary.MaxVarintLen64]byte
+	b.mustWrite(scratch[:binary.PutVarint(scratch[:], v)])
+}
+
+// next consumes the next n unread bytes, or returns the error described above
+// without consuming anything.
+func (b *Bytes) next(n int) (p []byte, err error) {
+	b.lastRead = opInvalid
+	if b.empty() {
+		b.Reset()
+		return nil, io.EOF
+	}
+	if b.Len() < n {
+		return nil, io.ErrUnexpectedEOF
+	}
+	p = b.B[b.off : b.off+n]
+	b.off += n
+	b.lastRead = opRead
+	return
+}
+
+// ReadUint16BE consumes a big-endian uint16.
+func (b *Bytes) ReadUint16BE() (v uint16, err error) {
+	p, err := b.next(2)
+	if err != nil {
+		return
+	}
+	return binary.BigEndian.Uint16(p), nil
+}
+
+// ReadUint32BE consumes a big-endian uint32.
+func (b *Bytes) ReadUint32BE() (v uint32, err error) {
+	p, err := b.next(4)
+	if err != nil {
+		return
+	}
+	return binary.BigEndian.Uint32(p), nil
+}
+
+// ReadUint64BE consumes a big-endian uint64.
+func (b *Bytes) ReadUint64BE() (v uint64, err error) {
+	p, err := b.next(8)
+	if err != nil {
+		return
+	}
+	return binary.BigEndian.Uint64(p), nil
+}
+
+// ReadUint16LE consumes a little-endian uint16.
+func (b *Bytes) ReadUint16LE() (v uint16, err error) {
+	p, err := b.next(2)
+	if err != nil {
+		return
+	}
+	return binary.LittleEndian.Uint16(p), nil
+}
+
+// ReadUint32LE consumes a little-endian uint32.
+func (b *Bytes) ReadUint32LE() (v uint32, err error) {
+	p, err := b.next(4)
+	if err != nil {
+		return
+	}
+	return binary.LittleEndian.Uint32(p), nil
+}
+
+// ReadUint64LE consumes a little-endian uint64.
+func (b *Bytes) ReadUint64LE() (v uint64, err error) {
+	p, err := b.next(8)
+	if err != nil {
+		return
+	}
+	return binary.LittleEndian.Uint64(p), nil
+}
+
+// ReadUvarint consumes a varint-encoded uint64.
+func (b *Bytes) ReadUvarint() (v uint64, err error) {
+	b.lastRead = opInvalid
+	if b.empty() {
+		b.Reset()
+		return 0, io.EOF
+	}
+	v, n := binary.Uvarint(b.B[b.off:])
+	if n == 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	if n < 0 {
+		return 0, ErrVarintOverflow
+	}
+	b.off += n
+	b.lastRead = opRead
+	return
+}
+
+// ReadVarint consumes a zig-zag varint-encoded int64.
+func (b *Bytes) ReadVarint() (v int64, err error) {
+	uv, err := b.ReadUvarint()
+	if err != nil {
+		return
+	}
+	v = int64(uv >> 1)
+	if uv&1 != 0 {
+		v = ^v
+	}
+	return
+}
@@ -0,0 +1,99 @@
+package bpool
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestBytes_BinaryRoundTrip(t *testing.T) {
+	pb := Get(0)
+	defer pb.Release()
+	pb.AppendUint16BE(0x0102)
+	pb.AppendUint32BE(0x03040506)
+	pb.AppendUint64BE(0x0708090a0b0c0d0e)
+	pb.AppendUint16LE(0x0102)
+	pb.AppendUint32LE(0x03040506)
+	pb.AppendUint64LE(0x0708090a0b0c0d0e)
+	pb.AppendUvarint(300)
+	pb.AppendVarint(-300)
+	pb.AppendVarint(math.MinInt64)
+
+	var want []byte
+	want = binary.BigEndian.AppendUint16(want, 0x0102)
+	want = binary.BigEndian.AppendUint32(want, 0x03040506)
+	want = binary.BigEndian.AppendUint64(want, 0x0708090a0b0c0d0e)
+	want = binary.LittleEndian.AppendUint16(want, 0x0102)
+	want = binary.LittleEndian.AppendUint32(want, 0x03040506)
+	want = binary.LittleEndian.AppendUint64(want, 0x0708090a0b0c0d0e)
+	want = binary.AppendUvarint(want, 300)
+	want = binary.AppendVarint(want, -300)
+	want = binary.AppendVarint(want, math.MinInt64)
+	assert.Eq(t, want, pb.Bytes())
+
+	v16, err := pb.ReadUint16BE()
+	assert.NoErr(t, err)
+	assert.Eq(t, uint16(0x0102), v16)
+	v32, _ := pb.ReadUint32BE()
+	assert.Eq(t, uint32(0x03040506), v32)
+	v64, _ := pb.ReadUint64BE()
+	assert.Eq(t, uint64(0x0708090a0b0c0d0e), v64)
+	v16, _ = pb.ReadUint16LE()
+	assert.Eq(t, uint16(0x0102), v16)
+	v32, _ = pb.ReadUint32LE()
+	assert.Eq(t, uint32(0x03040506), v32)
+	v64, _ = pb.ReadUint64LE()
+	assert.Eq(t, uint64(0x0708090a0b0c0d0e), v64)
+	uv, err := pb.ReadUvarint()
+	assert.NoErr(t, err)
+	assert.Eq(t, uint64(300), uv)
+	iv, _ := pb.ReadVarint()
+	assert.Eq(t, int64(-300), iv)
+	iv, _ = pb.ReadVarint()
+	assert.Eq(t, int64(math.MinInt64), iv)
+
+	_, err = pb.ReadUint32BE()
+	assert.Equal(t, io.EOF, err)
+	_, err = pb.ReadUvarint()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestBytes_BinaryReadShort(t *testing.T) {
+	pb := Get(0)
+	defer pb.Release()
+	pb.Write([]byte{1, 2, 3})
+	_, err := pb.ReadUint32LE()
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+	assert.Eq(t, 3, pb.Len())
+	v16, err := pb.ReadUint16LE()
+	assert.NoErr(t, err)
+	assert.Eq(t, uint16(0x0201), v16)
+	assert.NoErr(t, pb.UnreadByte())
+	assert.Eq(t, 2, pb.Len())
+
+	pb.Reset()
+	pb.Write([]byte{0x80, 0x80})
+	_, err = pb.ReadUvarint()
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+	assert.Eq(t, 2, pb.Len())
+
+	pb.Reset()
+	pb.Write([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01})
+	_, err = pb.ReadUvarint()
+	assert.Equal(t, ErrVarintOverflow, err)
+	assert.Eq(t, 11, pb.Len())
+}
+
+func TestBytes_BinaryAppendLimit(t *testing.T) {
+	pb := Get(64)
+	defer pb.Release()
+	pb.SetMaxSize(5)
+	pb.AppendUint32BE(1)
+	assert.PanicsErrMsg(t, func() { pb.AppendUint16LE(1) }, ErrTooLarge.Error())
+	pb.AppendUvarint(1)
+	assert.PanicsErrMsg(t, func() { pb.AppendVarint(1) }, ErrTooLarge.Error())
+	assert.Eq(t, 5, pb.Len())
+}
@@ -1,6 +1,8 @@
 package bpool
 
 import (
+	"errors"
+	"github.com/gookit/goutil/testutil/assert"
 	"math/rand"
 	"slices"
 	"testing"
@@ -84,3 +86,219 @@ func TestSmallPut(t *testing.T) {
 		t.Fatal("not find 35 cap buf")
 	}
 }
+
+func TestDefaultClassesMatchSize2class(t *testing.T) {
+	for i := 0; i <= _MaxBigSize; i++ {
+		if defaultClasses.sizeToClass(i) != size2class(i) {
+			t.Fatalf("sizeToClass(%d) = %d, size2class = %d", i, defaultClasses.sizeToClass(i), size2class(i))
+		}
+	}
+}
+
+func TestNewWithClasses(t *testing.T) {
+	classes := []int{16, 100, 1000, 1400, 4000, 40000, 70000, 100000, 3 << 20}
+	bp, err := NewWithClasses(classes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(bp.Classes(), classes) {
+		t.Fatalf("Classes() = %v, want %v", bp.Classes(), classes)
+	}
+	for i := 1; i <= classes[len(classes)-1]; i++ {
+		want := classes[slices.IndexFunc(classes, func(c int) bool { return c >= i })]
+		pb := bp.Get(i)
+		if cap(pb.B) != want {
+			t.Fatalf("Get(%d) cap = %d, want %d", i, cap(pb.B), want)
+		}
+		bp.Put(pb)
+	}
+	pb := bp.Get(3<<20 + 1)
+	if cap(pb.B) != 3<<20+1 {
+		t.Fatalf("Get above the last class cap = %d", cap(pb.B))
+	}
+}
+
+func TestNewWithClassesInvalid(t *testing.T) {
+	// Wraps around to a negative size on 32-bit platforms.
+	tooBig := int64(_MaxClassSize) + 1
+	for _, classes := range [][]int{
+		nil,
+		{0, 32},
+		{64, 32},
+		{32, 32},
+		{-1},
+		{int(tooBig)},
+		make([]int, 256),
+		// Nine classes share one lookup range above 32 KB.
+		{1<<20 + 1, 1<<20 + 2, 1<<20 + 3, 1<<20 + 4, 1<<20 + 5, 1<<20 + 6, 1<<20 + 7, 1<<20 + 8, 1<<20 + 9},
+	} {
+		if _, err := NewWithClasses(classes); !errors.Is(err, ErrInvalidClasses) {
+			t.Fatalf("NewWithClasses(%v) err = %v", classes, err)
+		}
+	}
+}
+
+func TestNewWithClassesPutDemote(t *testing.T) {
+	bp, err := NewWithClasses([]int{100, 200})
+	if err != nil {
+		t.Fatal(err)
+	}
+	find := false
+	for i := 0; i < 100; i++ {
+		bp.Put(&Bytes{B: make([]byte, 0, 150)})
+		bp.Put(&Bytes{B: make([]byte, 0, 50)})
+		if cap(bp.Get(100).B) == 150 {
+			find = true
+		}
+	}
+	if !find {
+		t.Fatal("not find 150 cap buf")
+	}
+}
+
+func TestStats(t *testing.T) {
+	bp, err := NewWithClasses([]int{64, 128, 1 << 16, 1 << 17}, WithStats())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pb := bp.Get(100)
+	bp.Put(pb)
+	bp.Put(&Bytes{B: make([]byte, 0, 100)})
+	bp.Put(&Bytes{B: make([]byte, 0, 1<<16+1)})
+	bp.Put(&Bytes{B: make([]byte, 0, 32)})
+	bp.Put(&Bytes{B: make([]byte, 0, 1<<18)})
+	bp.Get(1 << 18)
+	s := bp.Stats()
+	assert.Len(t, s.Classes, 4)
+	assert.Equal(t, ClassStats{Size: 64, Puts: 1}, s.Classes[0])
+	assert.Equal(t, uint64(1), s.Classes[1].Misses)
+	assert.Equal(t, uint64(1), s.Classes[1].Puts)
+	assert.Equal(t, uint64(1), s.Classes[2].Puts)
+	assert.Equal(t, uint64(2), s.RejectedPuts)
+	assert.Equal(t, uint64(1), s.Oversized)
+	for i := 0; i < 10; i++ {
+		bp.Put(bp.Get(128))
+	}
+	s = bp.Stats()
+	if s.Classes[1].Hits == 0 {
+		t.Fatal("no hits recorded")
+	}
+	assert.Equal(t, s.Classes[1].Hits+s.Classes[1].Misses, uint64(11))
+
+	bp = New()
+	bp.Put(bp.Get(100))
+	s = bp.Stats()
+	assert.Len(t, s.Classes, len(class_to_size)-1)
+	assert.Equal(t, ClassStats{Size: 128}, s.Classes[3])
+}
+
+func TestNewWithClassesExact(t *testing.T) {
+	for _, tc := range []struct {
+		classes []int
+		exact   bool
+	}{
+		{[]int{32, 1024, 1152, 1 << 16, 1<<17 + 1}, true},
+		{[]int{32, 1020, 2048}, false},
+		{[]int{32, 1100, 2048}, false},
+		{[]int{32, 1 << 16, 98304, 1 << 17}, true},
+		{[]int{32, 1 << 16, 100000, 1 << 17}, false},
+	} {
+		bp, err := NewWithClasses(tc.classes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bp.table.Load().classes.exact != tc.exact {
+			t.Fatalf("NewWithClasses(%v) exact = %v", tc.classes, bp.table.Load().classes.exact)
+		}
+		for i := 1; i <= tc.classes[len(tc.classes)-1]; i++ {
+			want := tc.classes[slices.IndexFunc(tc.classes, func(c int) bool { return c >= i })]
+			if got := int(bp.table.Load().classes.classToSize[bp.table.Load().classes.sizeToClass(i)]); got != want {
+				t.Fatalf("%v: class of %d = %d, want %d", tc.classes, i, got, want)
+			}
+		}
+	}
+}
+
+func TestNewWithClassesDenseBig(t *testing.T) {
+	// 250 classes 64 bytes apart would need up to 250 steps past one big
+	// lookup entry.
+	dense := make([]int, 250)
+	for i := range dense {
+		dense[i] = 1<<20 + i*64
+	}
+	_, err := NewWithClasses(dense)
+	assert.ErrIs(t, err, ErrInvalidClasses)
+
+	// Classes a byte apart are fine below 32 KB, where every lookup entry
+	// covers 8 sizes.
+	small := make([]int, 255)
+	for i := range small {
+		small[i] = 4000 + i
+	}
+	bp, err := NewWithClasses(small)
+	assert.NoErr(t, err)
+	sc := bp.table.Load().classes
+	for i := 1; i <= small[len(small)-1]; i++ {
+		assert.Eq(t, max(i, small[0]), int(sc.classToSize[sc.sizeToClass(i)]))
+	}
+
+	// Four classes in each lookup range of the 1 MB power of two are fine.
+	var classes []int
+	for size := 1<<19 + 1<<13; size <= 1<<20; size += 1 << 13 {
+		classes = append(classes, size)
+	}
+	bp, err = NewWithClasses(classes)
+	assert.NoErr(t, err)
+	sc = bp.table.Load().classes
+	for i := 1; i <= classes[len(classes)-1]; i += 97 {
+		want := classes[slices.IndexFunc(classes, func(c int) bool { return c >= i })]
+		assert.Eq(t, want, int(sc.classToSize[sc.sizeToClass(i)]))
+	}
+}
+
+func TestFineClasses(t *testing.T) {
+	classes := FineClasses()
+	assert.Len(t, classes, len(class_to_size)-1-8+8*4)
+	bp, err := NewWithClasses(classes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pb := bp.Get(65 << 10)
+	assert.Eq(t, 80<<10, cap(pb.B))
+	for i := _MaxSmallSize + 1; i <= _MaxBigSize; i += 997 {
+		want := classes[slices.IndexFunc(classes, func(c int) bool { return c >= i })]
+		if got := int(bp.table.Load().classes.classToSize[bp.table.Load().classes.sizeToClass(i)]); got != want {
+			t.Fatalf("class of %d = %d, want %d", i, got, want)
+		}
+	}
+}
+
+func TestBigPutDemote(t *testing.T) {
+	bp := New()
+	find := false
+	for i := 0; i < 100; i++ {
+		bp.Put(&Bytes{B: make([]byte, 0, 100<<10)})
+		if cap(bp.Get(64<<10).B) == 100<<10 {
+			find = true
+		}
+	}
+	if !find {
+		t.Fatal("not find 100K cap buf")
+	}
+}
+
+func TestFastPathSelection(t *testing.T) {
+	bp := New()
+	assert.True(t, bp.table.Load().fixed != nil)
+	pb := bp.Get(100)
+	assert.Eq(t, 128, cap(pb.B))
+	bp.Put(pb)
+
+	assert.Nil(t, New(WithStats()).table.Load().fixed)
+	assert.Nil(t, New(WithSizeHistogram()).table.Load().fixed)
+	assert.Nil(t, New(WithRetention(Retention{MaxBuffers: 1})).table.Load().fixed)
+
+	assert.NoErr(t, bp.SetClasses([]int{64, 256}))
+	assert.Nil(t, bp.table.Load().fixed)
+	assert.Eq(t, 256, cap(bp.Get(100).B))
+}
@@ -0,0 +1,208 @@
+package bpool
+
+import (
+	"io"
+	"net"
+)
+
+// DefaultChunkSize is the chunk size of a Chain created without one.
+const DefaultChunkSize = 32 << 10
+
+// Chain is a buffer made of fixed-size chunks taken from the pool. Unlike a
+// Bytes, it never copies its content to grow: writes fill the last chunk and
+// then add a new one, so building a multi-megabyte body costs one copy of
+// each byte. Reads consume from the first chunk, and drained chunks go back
+// to the pool right away. The zero value is an empty Chain using
+// DefaultChunkSize and the default Allocator.
+type Chain struct {
+	alloc     Allocator
+	chunkSize int
+	// chunks[head:] hold the content; each chunk tracks its own read offset.
+	chunks []*Bytes
+	head   int
+	n      int
+	vec    [][]byte // reused by WriteTo
+}
+
+// NewChain returns an empty Chain whose chunks hold at least chunkSize bytes;
+// chunkSize <= 0 selects DefaultChunkSize.
+func NewChain(chunkSize int) *Chain {
+	return &Chain{chunkSize: chunkSize}
+}
+
+// SetAllocator makes c take its chunks from a and return them to it. It must
+// be called while c is empty.
+func (c *Chain) SetAllocator(a Allocator) {
+	c.alloc = a
+}
+
+func (c *Chain) allocator() Allocator {
+	if c.alloc != nil {
+		return c.alloc
+	}
+	return defaultAllocator
+}
+
+// Len returns the number of unread bytes.
+func (c *Chain) Len() int {
+	return c.n
+}
+
+// tail returns the last chunk if it has room left, otherwise a new chunk.
+func (c *Chain) tail() *Bytes {
+	if k := len(c.chunks); k > c.head {
+		if last := c.chunks[k-1]; len(last.B) < cap(last.B) {
+			return last
+		}
+	}
+	size := c.chunkSize
+	if size <= 0 {
+		size = DefaultChunkSize
+	}
+	chunk := c.allocator().Get(size)
+	c.chunks = append(c.chunks, chunk)
+	return chunk
+}
+
+// Write appends p to c. It always returns len(p), nil.
+func (c *Chain) Write(p []byte) (n int, err error) {
+	n = len(p)
+	for len(p) > 0 {
+		chunk := c.tail()
+		m := copy(chunk.B[len(chunk.B):cap(chunk.B)], p)
+		chunk.B = chunk.B[:len(chunk.B)+m]
+		p = p[m:]
+	}
+	c.n += n
+	return
+}
+
+// WriteString appends s to c. It always returns len(s), nil.
+func (c *Chain) WriteString(s string) (n int, err error) {
+	n = len(s)
+	for len(s) > 0 {
+		chunk := c.tail()
+		m := copy(chunk.B[len(chunk.B):cap(chunk.B)], s)
+		chunk.B = chunk.B[:len(chunk.B)+m]
+		s = s[m:]
+	}
+	c.n += n
+	return
+}
+
+// Read reads the next len(p) bytes from c or until c is drained. If c has no
+// data to return, err is io.EOF (unless len(p) is zero).
+func (c *Chain) Read(p []byte) (n int, err error) {
+	if c.n == 0 {
+		if len(p) == 0 {
+			return 0, nil
+		}
+		return 0, io.EOF
+	}
+	for n < len(p) && c.n > 0 {
+		chunk := c.chunks[c.head]
+		m := copy(p[n:], chunk.B[chunk.off:])
+		chunk.off += m
+		n += m
+		c.n -= m
+		c.dropDrained()
+	}
+	return
+}
+
+// WriteTo writes the content of c to w with a single vectored write where w
+// supports it, see writeBuffers. The written bytes are consumed, and the
+// chunks they fill are released, even if w fails part way. A short write
+// without an error is reported as io.ErrShortWrite.
+func (c *Chain) WriteTo(w io.Writer) (n int64, err error) {
+	c.vec = c.vec[:0]
+	var total int64
+	for _, chunk := range c.chunks[c.head:] {
+		if p := chunk.B[chunk.off:]; len(p) > 0 {
+			c.vec = append(c.vec, p)
+			total += int64(len(p))
+		}
+	}
+	n, err = writeBuffers(w, c.vec)
+	clear(c.vec)
+	if n > total {
+		panic("bpool.Chain.WriteTo: invalid Write count")
+	}
+	c.consume(int(n))
+	if err == nil && n != total {
+		err = io.ErrShortWrite
+	}
+	return
+}
+
+// writeBuffers writes vec to w. The connections of package net get a single
+// vectored write through net.Buffers. Other writers get one Write per buffer,
+// stopping at the first one that writes less than the whole buffer: unlike
+// net.Buffers, it never goes on to the next buffer after a short write.
+func writeBuffers(w io.Writer, vec [][]byte) (n int64, err error) {
+	switch w.(type) {
+	case *net.TCPConn, *net.UnixConn, *net.UDPConn, *net.IPConn:
+		bufs := net.Buffers(vec)
+		return bufs.WriteTo(w)
+	}
+	for _, p := range vec {
+		m, e := w.Write(p)
+		if m < 0 || m > len(p) {
+			panic("bpool: invalid Write count")
+		}
+		n += int64(m)
+		if e != nil || m != len(p) {
+			return n, e
+		}
+	}
+	return
+}
+
+// consume discards the first n unread bytes.
+func (c *Chain) consume(n int) {
+	c.n -= n
+	for n > 0 {
+		chunk := c.chunks[c.head]
+		m := min(n, len(chunk.B)-chunk.off)
+		chunk.off += m
+		n -= m
+		c.dropDrained()
+	}
+}
+
+// dropDrained releases the first chunk once it has been read completely. The
+// last chunk is kept for further writes while it has room left.
+func (c *Chain) dropDrained() {
+	chunk := c.chunks[c.head]
+	if chunk.off < len(chunk.B) {
+		return
+	}
+	if c.head == len(c.chunks)-1 && len(chunk.B) < cap(chunk.B) {
+		chunk.Reset()
+		return
+	}
+	c.chunks[c.head] = nil
+	c.head++
+	if c.head*2 >= len(c.chunks) {
+		// Move the live chunks to the front so that a chain that is read
+		// as fast as it is written does not keep growing c.chunks.
+		k := copy(c.chunks, c.chunks[c.head:])
+		clear(c.chunks[k:])
+		c.chunks = c.chunks[:k]
+		c.head = 0
+	}
+	c.allocator().Put(chunk)
+}
+
+// Release returns all chunks to the pool and leaves c empty and ready for
+// reuse.
+func (c *Chain) Release() {
+	a := c.allocator()
+	for i, chunk := range c.chunks[c.head:] {
+		a.Put(chunk)
+		c.chunks[c.head+i] = nil
+	}
+	c.chunks = c.chunks[:0]
+	c.head = 0
+	c.n = 0
+}
@@ -0,0 +1,130 @@
+package bpool
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestChain_WriteRead(t *testing.T) {
+	ca := &countingAllocator{Allocator: New()}
+	c := NewChain(1000)
+	c.SetAllocator(ca)
+	data := bytes.Repeat([]byte("0123456789"), 1000)
+	var want bytes.Buffer
+	for i := 0; i < len(data); i += 777 {
+		p := data[i:min(i+777, len(data))]
+		n, err := c.Write(p)
+		assert.NoErr(t, err)
+		assert.Eq(t, len(p), n)
+		c.WriteString("|")
+		want.Write(p)
+		want.WriteString("|")
+	}
+	assert.Eq(t, want.Len(), c.Len())
+
+	// Interleave small and large reads.
+	var got []byte
+	buf := make([]byte, 1500)
+	for i := 0; ; i++ {
+		n, err := c.Read(buf[:1+i*97%len(buf)])
+		got = append(got, buf[:n]...)
+		if err == io.EOF {
+			break
+		}
+		assert.NoErr(t, err)
+	}
+	assert.Eq(t, want.Bytes(), got)
+	assert.Eq(t, 0, c.Len())
+	// Only the last chunk, kept for writing, is still out.
+	assert.Eq(t, ca.gets-1, ca.puts)
+	c.Release()
+	assert.Eq(t, ca.gets, ca.puts)
+}
+
+func TestChain_WriteTo(t *testing.T) {
+	var c Chain
+	data := bytes.Repeat([]byte("abc"), 50000)
+	c.Write(data)
+	var out bytes.Buffer
+	n, err := c.WriteTo(&out)
+	assert.NoErr(t, err)
+	assert.Eq(t, int64(len(data)), n)
+	assert.Eq(t, data, out.Bytes())
+	assert.Eq(t, 0, c.Len())
+
+	// Writing resumes after the chain was drained.
+	c.WriteString("more")
+	out.Reset()
+	c.WriteTo(&out)
+	assert.Eq(t, "more", out.String())
+	c.Release()
+}
+
+type shortWriter struct {
+	limit int
+	buf   bytes.Buffer
+}
+
+func (w *shortWriter) Write(p []byte) (int, error) {
+	if w.buf.Len()+len(p) > w.limit {
+		p = p[:w.limit-w.buf.Len()]
+		w.buf.Write(p)
+		return len(p), errors.New("short")
+	}
+	return w.buf.Write(p)
+}
+
+func TestChain_WriteToPartial(t *testing.T) {
+	c := NewChain(100)
+	data := bytes.Repeat([]byte("x0123456789"), 100)
+	c.Write(data)
+	w := &shortWriter{limit: 550}
+	n, err := c.WriteTo(w)
+	assert.Err(t, err)
+	assert.Eq(t, int64(550), n)
+	assert.Eq(t, len(data)-550, c.Len())
+	rest, _ := io.ReadAll(c)
+	assert.Eq(t, data[550:], rest)
+	c.Release()
+}
+
+func TestChain_WriteToShort(t *testing.T) {
+	c := NewChain(32)
+	defer c.Release()
+	c.WriteString(strings.Repeat("a", 32))
+	c.WriteString(strings.Repeat("b", 32))
+	w := &quietShortWriter{}
+	n, err := c.WriteTo(w)
+	assert.Equal(t, io.ErrShortWrite, err)
+	assert.Eq(t, int64(16), n)
+	assert.Eq(t, strings.Repeat("a", 16), w.String())
+	assert.Eq(t, 48, c.Len())
+
+	var out bytes.Buffer
+	n, err = c.WriteTo(&out)
+	assert.NoErr(t, err)
+	assert.Eq(t, int64(48), n)
+	assert.Eq(t, strings.Repeat("a", 16)+strings.Repeat("b", 32), out.String())
+}
+
+func TestChain_ReadInterleaved(t *testing.T) {
+	c := NewChain(64)
+	var want, got bytes.Buffer
+	buf := make([]byte, 50)
+	for i := 0; i < 1000; i++ {
+		p := bytes.Repeat([]byte{byte(i)}, 40)
+		c.Write(p)
+		want.Write(p)
+		n, _ := c.Read(buf)
+		got.Write(buf[:n])
+	}
+	io.Copy(&got, c)
+	assert.Eq(t, want.Bytes(), got.Bytes())
+	assert.True(t, len(c.chunks) < 8)
+	c.Release()
+}
@@ -0,0 +1,295 @@
+// Command sizeclassgen generates the size-class tables of package bpool.
+//
+// It writes sizeclass_table.go, holding the class sizes and the lookup tables
+// used by size2class, and sizeclass_table_test.go, which checks that every
+// size maps to the smallest class that fits. Run it through go generate in
+// the package directory:
+//
+//	//go:generate go run ./cmd/sizeclassgen
+//
+// The small classes default to the built-in list and can be replaced with
+// -classes. Above 1<<-max-small-power the classes are the powers of two up to
+// 1<<-max-power; fine_class_to_size splits each of those steps into
+// -fine-steps classes.
+//
+// With -report, sizeclassgen writes no files and instead prints the internal
+// fragmentation of the configured table (or of the fine table with -fine)
+// for the size distribution in the named file, one "size [count]" pair per
+// line:
+//
+//	go run ./cmd/sizeclassgen -report sizes.txt
+//
+// For every class the report lists the worst-case fragmentation (of the
+// smallest size the class serves), the average fragmentation of the requests
+// it served, the number of those requests and distinct sizes, and the bytes
+// they wasted.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"math/bits"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var defaultSmallClasses = []int{32, 64, 96, 128, 160, 224, 256, 320, 384, 448, 512, 640, 768, 1024, 1280, 1536, 1792, 2048, 2304, 2688, 3072, 3456, 4096, 4864, 5376, 6144, 6784, 8192, 9472, 10240, 10880, 12288, 13568, 14336, 16384, 18432, 19072, 20480, 21760, 24576, 27264, 28672, 32768}
+
+type config struct {
+	pkg           string
+	out           string
+	report        string
+	fine          bool
+	testOut       string
+	minSize       int
+	smallSizeDiv  int
+	smallSizeMax  int
+	largeSizeDiv  int
+	maxSmallPower int
+	maxBigPower   int
+	fineSteps     int
+	classes       []int
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("sizeclassgen: ")
+	c := config{}
+	classes := ""
+	flag.StringVar(&c.pkg, "pkg", "bpool", "package name of the generated files")
+	flag.StringVar(&c.out, "o", "sizeclass_table.go", "output file of the tables")
+	flag.StringVar(&c.testOut, "test", "sizeclass_table_test.go", "output file of the consistency test, empty to skip it")
+	flag.IntVar(&c.minSize, "min", 0, "smallest class size, the first class of the list if 0")
+	flag.IntVar(&c.smallSizeDiv, "small-div", 8, "lookup granularity up to -small-max")
+	flag.IntVar(&c.smallSizeMax, "small-max", 1024, "end of the -small-div lookup range")
+	flag.IntVar(&c.largeSizeDiv, "large-div", 128, "lookup granularity up to 1<<-max-small-power")
+	flag.IntVar(&c.maxSmallPower, "max-small-power", 15, "log2 of the largest small class")
+	flag.IntVar(&c.maxBigPower, "max-power", 23, "log2 of the largest class")
+	flag.IntVar(&c.fineSteps, "fine-steps", 4, "classes per power of two in fine_class_to_size")
+	flag.StringVar(&classes, "classes", "", "comma separated small class sizes, the built-in list if empty")
+	flag.StringVar(&c.report, "report", "", "print the fragmentation for the size distribution in this file (- for stdin) instead of generating")
+	flag.BoolVar(&c.fine, "fine", false, "report on fine_class_to_size instead of class_to_size")
+	flag.Parse()
+
+	c.classes = defaultSmallClasses
+	if classes != "" {
+		c.classes = nil
+		for _, f := range strings.Split(classes, ",") {
+			v, err := strconv.Atoi(strings.TrimSpace(f))
+			if err != nil {
+				log.Fatalf("invalid -classes: %v", err)
+			}
+			c.classes = append(c.classes, v)
+		}
+	}
+	if err := c.check(); err != nil {
+		log.Fatal(err)
+	}
+	if c.report != "" {
+		dist, err := readDistributionFile(c.report)
+		if err != nil {
+			log.Fatal(err)
+		}
+		table := c.allClasses()
+		if c.fine {
+			table = c.fineClasses()
+		}
+		if err = report(os.Stdout, table, dist); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if err := writeSource(c.out, c.table()); err != nil {
+		log.Fatal(err)
+	}
+	if c.testOut != "" {
+		if err := writeSource(c.testOut, c.test()); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// check validates the configuration and applies -min to the class list.
+func (c *config) check() error {
+	if c.minSize > 0 {
+		i, _ := slices.BinarySearch(c.classes, c.minSize)
+		c.classes = append([]int{c.minSize}, c.classes[i:]...)
+		if len(c.classes) > 1 && c.classes[1] == c.minSize {
+			c.classes = c.classes[1:]
+		}
+	}
+	if c.smallSizeDiv <= 0 || c.largeSizeDiv <= 0 || !isPowerOfTwo(c.smallSizeDiv) || !isPowerOfTwo(c.largeSizeDiv) {
+		return errors.New("-small-div and -large-div must be powers of two")
+	}
+	if c.smallSizeMax%c.largeSizeDiv != 0 || c.smallSizeMax%c.smallSizeDiv != 0 {
+		return errors.New("-small-max must be a multiple of -small-div and -large-div")
+	}
+	maxSmall := 1 << c.maxSmallPower
+	if maxSmall <= c.smallSizeMax || c.maxBigPower < c.maxSmallPower || c.maxBigPower > 31 {
+		return errors.New("need -small-max < 1<<-max-small-power <= 1<<-max-power <= 1<<31")
+	}
+	if c.fineSteps <= 0 || 1<<c.maxSmallPower%c.fineSteps != 0 {
+		return errors.New("-fine-steps must divide 1<<-max-small-power")
+	}
+	if len(c.classes) == 0 {
+		return errors.New("no classes")
+	}
+	if len(c.classes)+c.maxBigPower-c.maxSmallPower+1 > 256 {
+		return errors.New("too many classes, at most 255 are supported")
+	}
+	for i, v := range c.classes {
+		switch {
+		case v <= 0 || v > maxSmall:
+			return fmt.Errorf("class %d out of range (0, %d]", v, maxSmall)
+		case i > 0 && v <= c.classes[i-1]:
+			return fmt.Errorf("classes must be strictly increasing, got %d after %d", v, c.classes[i-1])
+		// Classes must end a lookup range so that the tables are exact.
+		case v <= c.smallSizeMax && v%c.smallSizeDiv != 0:
+			return fmt.Errorf("class %d not a multiple of -small-div", v)
+		case v > c.smallSizeMax && v%c.largeSizeDiv != 0:
+			return fmt.Errorf("class %d not a multiple of -large-div", v)
+		}
+	}
+	return nil
+}
+
+// allClasses returns the class sizes including class 0 and the big classes.
+func (c *config) allClasses() (classes []int) {
+	classes = append([]int{0}, c.classes...)
+	for p := c.maxSmallPower + 1; p <= c.maxBigPower; p++ {
+		classes = append(classes, 1<<p)
+	}
+	return
+}
+
+// fineClasses returns allClasses with every power-of-two step of the big
+// range split into fineSteps classes.
+func (c *config) fineClasses() (classes []int) {
+	classes = append([]int{0}, c.classes...)
+	for p := c.maxSmallPower; p < c.maxBigPower; p++ {
+		for q := c.fineSteps + 1; q <= 2*c.fineSteps; q++ {
+			if v := q << p / c.fineSteps; v > classes[len(classes)-1] {
+				classes = append(classes, v)
+			}
+		}
+	}
+	return
+}
+
+func (c *config) table() []byte {
+	classes := c.allClasses()
+	maxSmall := 1 << c.maxSmallPower
+	sizeToClass8 := make([]int, c.smallSizeMax/c.smallSizeDiv)
+	sizeToClass128 := make([]int, (maxSmall-c.smallSizeMax)/c.largeSizeDiv+1)
+	sizeToClassBig := make([]int, c.maxBigPower-c.maxSmallPower)
+	// Every entry maps the largest size of its lookup range to the
+	// smallest class that fits it.
+	classOf := func(size int) int {
+		return slices.IndexFunc(classes, func(v int) bool { return v >= size })
+	}
+	for i := range sizeToClass8 {
+		sizeToClass8[i] = classOf(i * c.smallSizeDiv)
+	}
+	for i := range sizeToClass128 {
+		sizeToClass128[i] = classOf(c.smallSizeMax + i*c.largeSizeDiv)
+	}
+	for i := range sizeToClassBig {
+		sizeToClassBig[i] = classOf(1 << (i + c.maxSmallPower + 1))
+	}
+
+	output := &bytes.Buffer{}
+	output.WriteString("// Code generated by sizeclassgen; DO NOT EDIT.\n\n")
+	output.WriteString("package " + c.pkg + "\n\n")
+	output.WriteString("const (\n")
+	fmt.Fprintf(output, "_MaxBigSizePower = %d\n", c.maxBigPower)
+	fmt.Fprintf(output, "_MaxSamllSizePower = %d\n", c.maxSmallPower)
+	output.WriteString("_MaxSmallSize = 1 << _MaxSamllSizePower\n")
+	output.WriteString("_MaxBigSize = 1 << _MaxBigSizePower\n")
+	fmt.Fprintf(output, "_MinByteSize = %d\n", c.classes[0])
+	fmt.Fprintf(output, "smallSizeDiv = %d\n", c.smallSizeDiv)
+	fmt.Fprintf(output, "smallSizeMax = %d\n", c.smallSizeMax)
+	fmt.Fprintf(output, "largeSizeDiv = %d\n", c.largeSizeDiv)
+	fmt.Fprintf(output, "_NumSizeClasses = %d\n", len(classes))
+	output.WriteString(")\n\n")
+
+	writeArray(output, "class_to_size", "[...]uint32", classes)
+	output.WriteString("// fine_class_to_size splits every power-of-two step above _MaxSmallSize\n")
+	output.WriteString("// into " + strconv.Itoa(c.fineSteps) + " classes, see FineClasses.\n//\n")
+	writeArray(output, "fine_class_to_size", "[...]uint32", c.fineClasses())
+	writeArray(output, "size_to_class8", "[smallSizeMax / smallSizeDiv]uint8", sizeToClass8)
+	writeArray(output, "size_to_class128", "[(_MaxSmallSize-smallSizeMax)/largeSizeDiv + 1]uint8", sizeToClass128)
+	writeArray(output, "size_to_class_big", "[_MaxBigSizePower - _MaxSamllSizePower]uint8", sizeToClassBig)
+	return output.Bytes()
+}
+
+func writeArray(output *bytes.Buffer, name, typ string, values []int) {
+	output.WriteString("//goland:noinspection GoSnakeCaseUsage\n")
+	output.WriteString("var " + name + " = " + typ + "{")
+	for i, v := range values {
+		if i > 0 {
+			output.WriteString(", ")
+		}
+		output.WriteString(strconv.Itoa(v))
+	}
+	output.WriteString("}\n\n")
+}
+
+func (c *config) test() []byte {
+	return []byte(`// Code generated by sizeclassgen; DO NOT EDIT.
+
+package ` + c.pkg + `
+
+import "testing"
+
+func TestSizeClassTable(t *testing.T) {
+	if len(class_to_size) != _NumSizeClasses || class_to_size[1] != _MinByteSize ||
+		class_to_size[len(class_to_size)-1] != _MaxBigSize {
+		t.Fatal("class_to_size does not match the constants")
+	}
+	class := uint8(0)
+	for size := 0; size <= _MaxBigSize; size++ {
+		for class_to_size[class] < uint32(size) {
+			class++
+		}
+		if got := size2class(size); got != class {
+			t.Fatalf("size2class(%d) = %d, want %d", size, got, class)
+		}
+	}
+}
+
+func TestFineClassTable(t *testing.T) {
+	bp, err := NewWithClasses(FineClasses())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := bp.table.Load().classes
+	class := uint8(0)
+	for size := 0; size <= _MaxBigSize; size++ {
+		for fine_class_to_size[class] < uint32(size) {
+			class++
+		}
+		if got := sc.sizeToClass(size); got != class {
+			t.Fatalf("sizeToClass(%d) = %d, want %d", size, got, class)
+		}
+	}
+}
+`)
+}
+
+func writeSource(name string, src []byte) error {
+	src, err := format.Source(src)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(name, src, 0o644)
+}
+
+func isPowerOfTwo(x int) bool {
+	return x > 0 && bits.OnesCount(uint(x)) == 1
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// distribution maps a requested size to how often it was requested.
+type distribution map[int]uint64
+
+// readDistribution parses a size distribution: one "size [count]" pair per
+// line, count defaulting to 1. Blank lines and lines starting with # are
+// ignored.
+func readDistribution(r io.Reader) (dist distribution, err error) {
+	dist = make(distribution)
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if len(fields) > 2 {
+			return nil, fmt.Errorf("line %d: want \"size [count]\"", line)
+		}
+		size, err := strconv.Atoi(fields[0])
+		if err != nil || size < 0 {
+			return nil, fmt.Errorf("line %d: invalid size %q", line, fields[0])
+		}
+		count := uint64(1)
+		if len(fields) == 2 {
+			if count, err = strconv.ParseUint(fields[1], 10, 64); err != nil {
+				return nil, fmt.Errorf("line %d: invalid count %q", line, fields[1])
+			}
+		}
+		dist[size] += count
+	}
+	return dist, sc.Err()
+}
+
+func readDistributionFile(name string) (distribution, error) {
+	if name == "-" {
+		return readDistribution(os.Stdin)
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	return readDistribution(f)
+}
+
+type classReport struct {
+	size int
+	// worst is the waste of the smallest size served by the class.
+	worst int
+	// requests and sizes count the requests and distinct sizes served.
+	requests uint64
+	sizes    int
+	wasted   uint64
+	asked    uint64
+}
+
+// report writes, for every class of classes (class 0 included), its
+// worst-case and average internal fragmentation and the requests of dist it
+// serves, followed by the totals.
+func report(w io.Writer, classes []int, dist distribution) error {
+	reports := make([]classReport, len(classes)-1)
+	for i := range reports {
+		reports[i] = classReport{size: classes[i+1], worst: classes[i+1] - classes[i] - 1}
+	}
+	var oversized, oversizedSizes uint64
+	for size, count := range dist {
+		if size == 0 {
+			continue
+		}
+		i := sort.SearchInts(classes[1:], size)
+		if i == len(reports) {
+			oversized += count
+			oversizedSizes++
+			continue
+		}
+		r := &reports[i]
+		r.requests += count
+		r.sizes++
+		r.wasted += count * uint64(r.size-size)
+		r.asked += count * uint64(size)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "class\tsize\tworst\tavg\trequests\tsizes\twasted bytes\t")
+	var total classReport
+	for i, r := range reports {
+		fmt.Fprintf(tw, "%d\t%d\t%s\t%s\t%d\t%d\t%d\t\n", i+1, r.size,
+			percent(uint64(r.worst), uint64(r.size)), percent(r.wasted, r.wasted+r.asked),
+			r.requests, r.sizes, r.wasted)
+		total.requests += r.requests
+		total.sizes += r.sizes
+		total.wasted += r.wasted
+		total.asked += r.asked
+	}
+	fmt.Fprintf(tw, "total\t\t\t%s\t%d\t%d\t%d\t\n", percent(total.wasted, total.wasted+total.asked),
+		total.requests, total.sizes, total.wasted)
+	if oversized > 0 {
+		fmt.Fprintf(tw, "oversized\t\t\t\t%d\t%d\t\t\n", oversized, oversizedSizes)
+	}
+	return tw.Flush()
+}
+
+// percent formats n/d as a percentage, or "-" if d is zero.
+func percent(n, d uint64) string {
+	if d == 0 {
+		return "-"
+	}
+	return strconv.FormatFloat(float64(n)*100/float64(d), 'f', 1, 64) + "%"
+}
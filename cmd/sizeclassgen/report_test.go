@@ -0,0 +1,45 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadDistribution(t *testing.T) {
+	dist, err := readDistribution(strings.NewReader("# sizes\n10 3\n\n20\n10 2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dist) != 2 || dist[10] != 5 || dist[20] != 1 {
+		t.Fatalf("unexpected distribution %v", dist)
+	}
+	for _, bad := range []string{"x", "10 y", "1 2 3", "-1"} {
+		if _, err = readDistribution(strings.NewReader(bad)); err == nil {
+			t.Fatalf("no error for %q", bad)
+		}
+	}
+}
+
+func TestReport(t *testing.T) {
+	sb := &strings.Builder{}
+	err := report(sb, []int{0, 32, 64}, distribution{24: 2, 32: 1, 40: 1, 100: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(sb.String()), "\n")
+	want := [][]string{
+		{"class", "size", "worst", "avg", "requests", "sizes", "wasted", "bytes"},
+		{"1", "32", "96.9%", "16.7%", "3", "2", "16"},
+		{"2", "64", "48.4%", "37.5%", "1", "1", "24"},
+		{"total", "25.0%", "4", "3", "40"},
+		{"oversized", "4", "1"},
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("report:\n%s", sb)
+	}
+	for i, line := range lines {
+		if got := strings.Fields(line); strings.Join(got, " ") != strings.Join(want[i], " ") {
+			t.Fatalf("line %d = %q, want %q", i, got, want[i])
+		}
+	}
+}
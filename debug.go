@@ -0,0 +1,106 @@
+package bpool
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// poisonByte fills buffers released to a debug pool, so stale readers see
+// obvious garbage instead of the next owner's data.
+const poisonByte = 0xdd
+
+const debugStackDepth = 32
+
+// WithDebug makes the pool check for misuse of released buffers. Put (and
+// Release) poisons the buffer contents and marks the *Bytes as released; a
+// second Put of the same *Bytes, or a Write, WriteString or Grow on it after
+// it was released, panics with the stack of the release and of the offending
+// call. Get panics if a cached buffer was modified while it sat in the pool.
+//
+// A released *Bytes is never handed out again, so the checks keep working
+// after its buffer has been reused. The checks are expensive and meant for
+// tests and debugging sessions only.
+func WithDebug() Option {
+	return func(c *poolConfig) {
+		c.debug = true
+	}
+}
+
+type debugInfo struct {
+	released bool
+	// putStack is where the buffer was last put to the pool.
+	putStack []uintptr
+}
+
+func callers(skip int) []uintptr {
+	pcs := make([]uintptr, debugStackDepth)
+	return pcs[:runtime.Callers(skip+1, pcs)]
+}
+
+func formatStack(sb *strings.Builder, pcs []uintptr) {
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		sb.WriteString("\t")
+		sb.WriteString(frame.Function)
+		sb.WriteString("\n\t\t")
+		sb.WriteString(frame.File)
+		sb.WriteString(":")
+		sb.WriteString(strconv.Itoa(frame.Line))
+		sb.WriteString("\n")
+		if !more {
+			break
+		}
+	}
+}
+
+func debugPanic(msg string, releasedAt []uintptr) {
+	sb := &strings.Builder{}
+	sb.WriteString("bpool: ")
+	sb.WriteString(msg)
+	sb.WriteString("\nreleased at:\n")
+	formatStack(sb, releasedAt)
+	sb.WriteString("called at:\n")
+	formatStack(sb, callers(3))
+	panic(sb.String())
+}
+
+// checkLive panics if b was released to a debug pool.
+func (b *Bytes) checkLive(op string) {
+	if b.dbg != nil && b.dbg.released {
+		debugPanic(op+" on *Bytes after it was released", b.dbg.putStack)
+	}
+}
+
+// debugGet verifies that b was not written to while it sat in the pool.
+func (bp *BytesPool) debugGet(b *Bytes) {
+	if b.dbg == nil {
+		b.dbg = &debugInfo{}
+		return
+	}
+	for _, c := range b.B[:cap(b.B)] {
+		if c != poisonByte {
+			debugPanic("buffer modified after it was released", b.dbg.putStack)
+		}
+	}
+	b.dbg.putStack = nil
+}
+
+// debugPut releases b and returns the fresh *Bytes that takes its buffer
+// into the pool.
+func (bp *BytesPool) debugPut(b *Bytes) (fresh *Bytes) {
+	if b.dbg == nil {
+		b.dbg = &debugInfo{}
+	} else if b.dbg.released {
+		debugPanic("*Bytes put to the pool twice", b.dbg.putStack)
+	}
+	stack := callers(3)
+	b.dbg.released = true
+	b.dbg.putStack = stack
+	full := b.B[:cap(b.B)]
+	for i := range full {
+		full[i] = poisonByte
+	}
+	return &Bytes{B: full[:0], alloc: bp, dbg: &debugInfo{putStack: stack}}
+}
@@ -0,0 +1,90 @@
+package bpool
+
+import (
+	"strings"
+	"testing"
+)
+
+func expectPanic(t *testing.T, contains string, f func()) {
+	t.Helper()
+	defer func() {
+		t.Helper()
+		r := recover()
+		if r == nil {
+			t.Fatalf("no panic, want %q", contains)
+		}
+		msg, _ := r.(string)
+		if !strings.Contains(msg, contains) || !strings.Contains(msg, "released at:") {
+			t.Fatalf("panic %v, want %q", r, contains)
+		}
+	}()
+	f()
+}
+
+func TestDebugDoublePut(t *testing.T) {
+	bp := New(WithDebug())
+	pb := bp.Get(100)
+	pb.Release()
+	expectPanic(t, "put to the pool twice", func() {
+		bp.Put(pb)
+	})
+}
+
+func TestDebugUseAfterPut(t *testing.T) {
+	bp := New(WithDebug())
+	pb := bp.Get(100)
+	_, _ = pb.Write([]byte("hello"))
+	b := pb.B
+	pb.Release()
+	for _, c := range b {
+		if c != poisonByte {
+			t.Fatalf("released buffer not poisoned: %q", b)
+		}
+	}
+	expectPanic(t, "Write on *Bytes after it was released", func() {
+		_, _ = pb.Write([]byte("x"))
+	})
+	expectPanic(t, "WriteString on *Bytes after it was released", func() {
+		pb.WriteString("x")
+	})
+	expectPanic(t, "Grow on *Bytes after it was released", func() {
+		pb.Grow(1 << 20)
+	})
+	// The released *Bytes stays released after its buffer is reused.
+	pb2 := bp.Get(100)
+	if pb2 == pb {
+		t.Fatal("released *Bytes handed out again")
+	}
+	expectPanic(t, "WriteString on *Bytes after it was released", func() {
+		pb.WriteString("x")
+	})
+}
+
+func TestDebugModifiedInPool(t *testing.T) {
+	bp := New(WithDebug())
+	expectPanic(t, "buffer modified after it was released", func() {
+		// sync.Pool may drop a put buffer, so retry until one comes back.
+		for i := 0; i < 100; i++ {
+			pb := bp.Get(100)
+			b := pb.B[:1]
+			pb.Release()
+			b[0] = 'x'
+			bp.Get(100)
+		}
+	})
+}
+
+func TestDebugGrowReleasesOldBuffer(t *testing.T) {
+	bp := New(WithDebug())
+	pb := bp.Get(32)
+	pb.WriteString("abc")
+	old := pb.B
+	pb.WriteString(strings.Repeat("x", 100))
+	if old[0] != poisonByte {
+		t.Fatal("buffer left behind by growth not poisoned")
+	}
+	if pb.String() != "abc"+strings.Repeat("x", 100) {
+		t.Fatalf("unexpected content %q", pb.String())
+	}
+	pb.Release()
+}
@@ -0,0 +1,242 @@
+package bpool
+
+import (
+	"bytes"
+	"unicode"
+	"unicode/utf8"
+)
+
+// The editing methods change the unread portion of the buffer in place, with
+// indexes relative to Bytes(). They take a new buffer from b's Allocator only
+// when the result does not fit in the capacity of ByteBuffer.B, and return
+// ErrTooLarge if it would exceed the limit set with SetMaxSize. The bytes
+// passed to them must not overlap ByteBuffer.B.
+
+// Insert inserts p at index at of the unread portion. It panics if at is out
+// of range.
+func (b *Bytes) Insert(at int, p []byte) error {
+	return b.splice("Insert", at, at, p)
+}
+
+// Delete removes the bytes [i:j) of the unread portion. It panics if the
+// range is invalid.
+func (b *Bytes) Delete(i, j int) {
+	//goland:noinspection GoUnhandledErrorResult
+	b.splice("Delete", i, j, nil)
+}
+
+// splice replaces the bytes [i:j) of the unread portion with p on behalf of
+// the method op.
+func (b *Bytes) splice(op string, i, j int, p []byte) error {
+	b.checkLive(op)
+	b.lastRead = opInvalid
+	if i < 0 || j < i || j > b.Len() {
+		panic("bpool.Bytes: edit out of range")
+	}
+	i += b.off
+	j += b.off
+	delta := len(p) - (j - i)
+	if delta > 0 && b.tooLarge(delta) {
+		return ErrTooLarge
+	}
+	oldLen := len(b.B)
+	newLen := oldLen + delta
+	if newLen > cap(b.B) {
+		alloc := b.Allocator()
+		b2 := alloc.Get(b.growSize(newLen + newLen>>1))
+		b2.B = b2.B[:newLen-b.off]
+		copy(b2.B, b.B[b.off:i])
+		copy(b2.B[i-b.off:], p)
+		copy(b2.B[i-b.off+len(p):], b.B[j:])
+		b.B, b2.B = b2.B, b.B
+		b.off = 0
+		alloc.Put(b2)
+		return nil
+	}
+	b.B = b.B[:max(oldLen, newLen)]
+	copy(b.B[i+len(p):], b.B[j:oldLen])
+	copy(b.B[i:], p)
+	b.B = b.B[:newLen]
+	return nil
+}
+
+// Replace replaces the first n non-overlapping instances of old with new in
+// the unread portion, with the semantics of bytes.Replace: if old is empty,
+// it matches at the beginning and after each UTF-8 sequence, and n < 0 means
+// no limit.
+func (b *Bytes) Replace(old, new []byte, n int) error {
+	b.checkLive("Replace")
+	b.lastRead = opInvalid
+	s := b.B[b.off:]
+	m := bytes.Count(s, old)
+	if m == 0 || n == 0 {
+		return nil
+	}
+	if n < 0 || m < n {
+		n = m
+	}
+	grow := n * (len(new) - len(old))
+	if grow > 0 && b.tooLarge(grow) {
+		return ErrTooLarge
+	}
+	newLen := len(b.B) + grow
+	switch {
+	case grow <= 0:
+		// Shrinking: the output never overtakes the input.
+		b.B = b.B[:b.off+replace(b.B[b.off:], s, old, new, n)]
+	case newLen <= cap(b.B):
+		// Move the input to the end of the capacity, then write the output
+		// from the front; it only catches up with the input at the end.
+		full := b.B[:cap(b.B)]
+		src := full[cap(b.B)-len(s):]
+		copy(src, s)
+		b.B = full[:b.off+replace(full[b.off:], src, old, new, n)]
+	default:
+		alloc := b.Allocator()
+		b2 := alloc.Get(b.growSize(newLen + newLen>>1))
+		b2.B = b2.B[:newLen-b.off]
+		replace(b2.B, s, old, new, n)
+		b.B, b2.B = b2.B, b.B
+		b.off = 0
+		alloc.Put(b2)
+	}
+	return nil
+}
+
+// ReplaceAll replaces all non-overlapping instances of old with new in the
+// unread portion, see Replace.
+func (b *Bytes) ReplaceAll(old, new []byte) error {
+	return b.Replace(old, new, -1)
+}
+
+// replace writes s with the first n instances of old replaced by new to dst,
+// the way bytes.Replace does, and returns the length written. dst may
+// overlap s as long as the output does not overtake the input.
+func replace(dst, s, old, new []byte, n int) (w int) {
+	start := 0
+	for i := 0; i < n; i++ {
+		j := start
+		if len(old) == 0 {
+			if i > 0 {
+				_, wid := utf8.DecodeRune(s[start:])
+				j += wid
+			}
+		} else {
+			j += bytes.Index(s[start:], old)
+		}
+		w += copy(dst[w:], s[start:j])
+		w += copy(dst[w:], new)
+		start = j + len(old)
+	}
+	w += copy(dst[w:], s[start:])
+	return
+}
+
+// TrimSpace removes the leading and trailing white space, as defined by
+// Unicode, from the unread portion.
+func (b *Bytes) TrimSpace() {
+	b.lastRead = opInvalid
+	s := b.B[b.off:]
+	t := bytes.TrimSpace(s)
+	if len(t) == 0 {
+		b.Reset()
+		return
+	}
+	b.off += cap(s) - cap(t)
+	b.B = b.B[:b.off+len(t)]
+}
+
+// TrimPrefix removes prefix from the start of the unread portion, if it is
+// there.
+func (b *Bytes) TrimPrefix(prefix []byte) {
+	b.lastRead = opInvalid
+	if bytes.HasPrefix(b.B[b.off:], prefix) {
+		b.off += len(prefix)
+	}
+}
+
+// TrimSuffix removes suffix from the end of the unread portion, if it is
+// there.
+func (b *Bytes) TrimSuffix(suffix []byte) {
+	b.lastRead = opInvalid
+	if bytes.HasSuffix(b.B[b.off:], suffix) {
+		b.B = b.B[:len(b.B)-len(suffix)]
+	}
+}
+
+// ToLower maps the letters of the unread portion to lower case, as
+// bytes.ToLower does, except that invalid UTF-8 is left as is. It panics
+// with ErrTooLarge in the rare case of a result longer than the limit set
+// with SetMaxSize.
+func (b *Bytes) ToLower() {
+	b.mapRunes("ToLower", unicode.ToLower, 'A', 'Z')
+}
+
+// ToUpper maps the letters of the unread portion to upper case, as
+// bytes.ToUpper does, except that invalid UTF-8 is left as is. It panics
+// with ErrTooLarge in the rare case of a result longer than the limit set
+// with SetMaxSize.
+func (b *Bytes) ToUpper() {
+	b.mapRunes("ToUpper", unicode.ToUpper, 'a', 'z')
+}
+
+// mapRunes applies mapping to each rune of the unread portion on behalf of
+// the method op, with a fast path switching the case of the ASCII letters lo
+// to hi. Runes whose mapping has the same encoded length are rewritten in
+// place; at the first one that does not, the rest is rebuilt in a new buffer.
+func (b *Bytes) mapRunes(op string, mapping func(rune) rune, lo, hi byte) {
+	b.checkLive(op)
+	b.lastRead = opInvalid
+	for i := b.off; i < len(b.B); {
+		c := b.B[i]
+		if c < utf8.RuneSelf {
+			if lo <= c && c <= hi {
+				b.B[i] = c ^ 0x20
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRune(b.B[i:])
+		if r == utf8.RuneError && size == 1 {
+			i++
+			continue
+		}
+		m := mapping(r)
+		if m == r {
+			i += size
+			continue
+		}
+		if utf8.RuneLen(m) != size {
+			b.remapFrom(i, mapping)
+			return
+		}
+		utf8.EncodeRune(b.B[i:], m)
+		i += size
+	}
+}
+
+// remapFrom rebuilds the unread portion from index i on with mapping in a
+// new buffer, for mappings that change the encoded length of a rune.
+func (b *Bytes) remapFrom(i int, mapping func(rune) rune) {
+	s := b.B[b.off:]
+	i -= b.off
+	alloc := b.Allocator()
+	b2 := alloc.Get(len(s) + len(s)>>1 + utf8.UTFMax)
+	b2.B = append(b2.B, s[:i]...)
+	for i < len(s) {
+		r, size := utf8.DecodeRune(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			b2.mustWrite(s[i : i+1])
+		} else {
+			_, _ = b2.WriteRune(mapping(r))
+		}
+		i += size
+	}
+	if b.maxSize > 0 && len(b2.B) > b.maxSize {
+		alloc.Put(b2)
+		panic(ErrTooLarge)
+	}
+	b.B, b2.B = b2.B, b.B
+	b.off = 0
+	alloc.Put(b2)
+}
@@ -0,0 +1,134 @@
+package bpool
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestBytes_InsertDelete(t *testing.T) {
+	pb := Get(16)
+	defer pb.Release()
+	pb.WriteString("xxhello world")
+	pb.Next(2)
+	assert.NoErr(t, pb.Insert(5, []byte(",")))
+	assert.NoErr(t, pb.Insert(0, []byte(">> ")))
+	assert.NoErr(t, pb.Insert(pb.Len(), []byte("!")))
+	assert.Eq(t, ">> hello, world!", pb.String())
+	pb.Delete(0, 3)
+	pb.Delete(5, 6)
+	assert.Eq(t, "hello world!", pb.String())
+
+	// Growing past the capacity keeps the content intact.
+	long := strings.Repeat("-", 100)
+	assert.NoErr(t, pb.Insert(5, []byte(long)))
+	assert.Eq(t, "hello"+long+" world!", pb.String())
+
+	assert.Panics(t, func() { pb.Insert(pb.Len()+1, nil) })
+	assert.Panics(t, func() { pb.Delete(3, 2) })
+
+	pb.SetMaxSize(len(pb.B))
+	assert.Equal(t, ErrTooLarge, pb.Insert(0, []byte("x")))
+}
+
+func TestBytes_Replace(t *testing.T) {
+	cases := []struct {
+		s, old, new string
+		n           int
+	}{
+		{"hello {{name}}, {{name}}!", "{{name}}", "Ann", -1},
+		{"hello {{name}}, {{name}}!", "{{name}}", "Bartholomew Longname", -1},
+		{"aaaa", "a", "bb", 2},
+		{"aaaa", "a", "", -1},
+		{"abc", "", "-", -1},
+		{"héllo", "", "|", 3},
+		{"no match", "zzz", "y", -1},
+		{"abc", "b", "x", 0},
+	}
+	for _, c := range cases {
+		want := string(bytes.Replace([]byte(c.s), []byte(c.old), []byte(c.new), c.n))
+		// Plenty of room, exact fit and a buffer that must grow, with and
+		// without a read prefix.
+		for _, size := range []int{256, len(c.s), 1} {
+			for _, prefix := range []string{"", "read:"} {
+				pb := Get(size)
+				pb.WriteString(prefix + c.s)
+				pb.Next(len(prefix))
+				assert.NoErr(t, pb.Replace([]byte(c.old), []byte(c.new), c.n))
+				assert.Eq(t, want, pb.String(), c)
+				pb.Release()
+			}
+		}
+	}
+	pb := Get(64)
+	defer pb.Release()
+	pb.WriteString("a-b-c")
+	assert.NoErr(t, pb.ReplaceAll([]byte("-"), []byte("::")))
+	assert.Eq(t, "a::b::c", pb.String())
+	pb.SetMaxSize(8)
+	assert.Equal(t, ErrTooLarge, pb.ReplaceAll([]byte("::"), []byte("---")))
+	assert.Eq(t, "a::b::c", pb.String())
+}
+
+func TestBytes_Trim(t *testing.T) {
+	pb := Get(32)
+	defer pb.Release()
+	pb.WriteString(" \t Content-Type: text/html\r\n")
+	pb.TrimSpace()
+	assert.Eq(t, "Content-Type: text/html", pb.String())
+	pb.TrimPrefix([]byte("Content-Type: "))
+	pb.TrimSuffix([]byte("/html"))
+	pb.TrimSuffix([]byte("nope"))
+	assert.Eq(t, "text", pb.String())
+	pb.Reset()
+	pb.WriteString(" \u00a0\n")
+	pb.TrimSpace()
+	assert.Eq(t, 0, pb.Len())
+}
+
+func TestBytes_ToLowerUpper(t *testing.T) {
+	for _, s := range []string{
+		"Hello, World",
+		"ÀÉÎÕÜ àéîõü",
+		"\u212a Kelvin and \u0130stanbul",
+		"\u0250 turned a and \u023a",
+		"mixed ASCII then \u212aelvin then MORE",
+	} {
+		for _, prefix := range []string{"", "READ"} {
+			pb := Get(len(s))
+			pb.WriteString(prefix + s)
+			pb.Next(len(prefix))
+			pb.ToLower()
+			assert.Eq(t, string(bytes.ToLower([]byte(s))), pb.String(), s)
+			pb.Reset()
+			pb.WriteString(prefix + s)
+			pb.Next(len(prefix))
+			pb.ToUpper()
+			assert.Eq(t, string(bytes.ToUpper([]byte(s))), pb.String(), s)
+			pb.Release()
+		}
+	}
+	pb := Get(8)
+	defer pb.Release()
+	pb.WriteString("A\xffB")
+	pb.ToLower()
+	assert.Eq(t, "a\xffb", pb.String())
+}
+
+func TestBytes_EditAllocs(t *testing.T) {
+	pb := Get(256)
+	defer pb.Release()
+	tmpl := []byte("Dear {{name}}, your order {{id}} has shipped.")
+	name, id := []byte("{{name}}"), []byte("{{id}}")
+	allocs := testing.AllocsPerRun(100, func() {
+		pb.Reset()
+		pb.Write(tmpl)
+		pb.ReplaceAll(name, []byte("Alexandra"))
+		pb.ReplaceAll(id, []byte("#1"))
+		pb.ToUpper()
+		pb.TrimSuffix([]byte("."))
+	})
+	assert.Eq(t, float64(0), allocs)
+}
@@ -0,0 +1,191 @@
+package bpool
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+)
+
+var errClosedEncoder = errors.New("bpool: Write after Close")
+
+// AppendBase64 appends src encoded with enc. The destination is sized with
+// Grow, so it panics with ErrTooLarge past the limit set with SetMaxSize.
+func (b *Bytes) AppendBase64(enc *base64.Encoding, src []byte) {
+	n := enc.EncodedLen(len(src))
+	b.mustGrow(n)
+	l := len(b.B)
+	b.B = b.B[:l+n]
+	enc.Encode(b.B[l:], src)
+}
+
+// AppendHex appends src encoded as lower-case hexadecimal. It panics with
+// ErrTooLarge past the limit set with SetMaxSize.
+func (b *Bytes) AppendHex(src []byte) {
+	n := hex.EncodedLen(len(src))
+	b.mustGrow(n)
+	l := len(b.B)
+	b.B = b.B[:l+n]
+	hex.Encode(b.B[l:], src)
+}
+
+// AppendBase64Decoded decodes src with enc and appends the result. If src is
+// malformed it returns the error of enc.Decode and appends nothing; it
+// returns ErrTooLarge if the result could exceed the limit set with
+// SetMaxSize.
+func (b *Bytes) AppendBase64Decoded(enc *base64.Encoding, src []byte) error {
+	if err := b.Grow(enc.DecodedLen(len(src))); err != nil {
+		return err
+	}
+	l := len(b.B)
+	n, err := enc.Decode(b.B[l:cap(b.B)], src)
+	if err != nil {
+		return err
+	}
+	b.B = b.B[:l+n]
+	return nil
+}
+
+// AppendHexDecoded decodes the hexadecimal src and appends the result. If
+// src is malformed it returns the error of hex.Decode and appends nothing; it
+// returns ErrTooLarge if the result would exceed the limit set with
+// SetMaxSize.
+func (b *Bytes) AppendHexDecoded(src []byte) error {
+	if err := b.Grow(hex.DecodedLen(len(src))); err != nil {
+		return err
+	}
+	l := len(b.B)
+	n, err := hex.Decode(b.B[l:cap(b.B)], src)
+	if err != nil {
+		return err
+	}
+	b.B = b.B[:l+n]
+	return nil
+}
+
+// Base64Writer is a streaming base64 encoder, like the one returned by
+// base64.NewEncoder, whose output buffer is taken from the pool. Data written
+// to it is encoded and passed on to the underlying writer in blocks; Close
+// flushes any partial block and returns the buffer to the pool, so it must
+// always be called.
+type Base64Writer struct {
+	enc  *base64.Encoding
+	w    io.Writer
+	out  *Bytes
+	buf  [3]byte // pending input that does not fill a quantum yet
+	nbuf int
+	err  error
+}
+
+// NewBase64Writer returns a Base64Writer encoding with enc into w.
+func NewBase64Writer(enc *base64.Encoding, w io.Writer) *Base64Writer {
+	return &Base64Writer{enc: enc, w: w, out: Get(Block4k)}
+}
+
+// Write encodes p. It returns the first error of the underlying writer.
+func (e *Base64Writer) Write(p []byte) (n int, err error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+	if e.out == nil {
+		return 0, errClosedEncoder
+	}
+	// Top up a pending quantum first.
+	if e.nbuf > 0 {
+		var i int
+		for i = 0; i < len(p) && e.nbuf < 3; i++ {
+			e.buf[e.nbuf] = p[i]
+			e.nbuf++
+		}
+		n += i
+		p = p[i:]
+		if e.nbuf < 3 {
+			return
+		}
+		out := e.out.B[:4]
+		e.enc.Encode(out, e.buf[:])
+		if _, e.err = e.w.Write(out); e.err != nil {
+			return n, e.err
+		}
+		e.nbuf = 0
+	}
+	// Large interior chunks.
+	out := e.out.B[:cap(e.out.B)]
+	for len(p) >= 3 {
+		nn := len(out) / 4 * 3
+		if nn > len(p) {
+			nn = len(p) - len(p)%3
+		}
+		e.enc.Encode(out, p[:nn])
+		if _, e.err = e.w.Write(out[:nn/3*4]); e.err != nil {
+			return n, e.err
+		}
+		n += nn
+		p = p[nn:]
+	}
+	// Keep the remainder for the next Write or Close.
+	e.nbuf = copy(e.buf[:], p)
+	n += len(p)
+	return
+}
+
+// Close flushes any pending output and releases the pooled buffer. It does
+// not close the underlying writer.
+func (e *Base64Writer) Close() error {
+	if e.out == nil {
+		return e.err
+	}
+	if e.err == nil && e.nbuf > 0 {
+		out := e.out.B[:e.enc.EncodedLen(e.nbuf)]
+		e.enc.Encode(out, e.buf[:e.nbuf])
+		_, e.err = e.w.Write(out)
+		e.nbuf = 0
+	}
+	e.out.Release()
+	e.out = nil
+	return e.err
+}
+
+// HexWriter is a streaming hexadecimal encoder, like the one returned by
+// hex.NewEncoder, whose output buffer is taken from the pool. Close returns
+// the buffer to the pool.
+type HexWriter struct {
+	w   io.Writer
+	out *Bytes
+	err error
+}
+
+// NewHexWriter returns a HexWriter encoding into w.
+func NewHexWriter(w io.Writer) *HexWriter {
+	return &HexWriter{w: w, out: Get(Block4k)}
+}
+
+// Write encodes p. It returns the first error of the underlying writer.
+func (e *HexWriter) Write(p []byte) (n int, err error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+	if e.out == nil {
+		return 0, errClosedEncoder
+	}
+	out := e.out.B[:cap(e.out.B)]
+	for len(p) > 0 {
+		nn := min(len(p), len(out)/2)
+		hex.Encode(out, p[:nn])
+		if _, e.err = e.w.Write(out[:nn*2]); e.err != nil {
+			return n, e.err
+		}
+		n += nn
+		p = p[nn:]
+	}
+	return
+}
+
+// Close releases the pooled buffer. It does not close the underlying writer.
+func (e *HexWriter) Close() error {
+	if e.out != nil {
+		e.out.Release()
+		e.out = nil
+	}
+	return e.err
+}
@@ -0,0 +1,98 @@
+package bpool
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestBytes_AppendBase64(t *testing.T) {
+	src := []byte("any carnal pleasure.")
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawURLEncoding} {
+		for n := 0; n <= len(src); n++ {
+			pb := Get(0)
+			pb.WriteString("x:")
+			pb.AppendBase64(enc, src[:n])
+			assert.Eq(t, "x:"+enc.EncodeToString(src[:n]), pb.String())
+
+			pb.Reset()
+			assert.NoErr(t, pb.AppendBase64Decoded(enc, []byte(enc.EncodeToString(src[:n]))))
+			assert.Eq(t, string(src[:n]), pb.String())
+			pb.Release()
+		}
+	}
+	pb := Get(0)
+	defer pb.Release()
+	pb.WriteString("keep")
+	assert.Err(t, pb.AppendBase64Decoded(base64.StdEncoding, []byte("!!!!")))
+	assert.Eq(t, "keep", pb.String())
+}
+
+func TestBytes_AppendHex(t *testing.T) {
+	pb := Get(0)
+	defer pb.Release()
+	pb.AppendHex([]byte{0x00, 0xab, 0xff})
+	assert.Eq(t, "00abff", pb.String())
+	pb.Reset()
+	assert.NoErr(t, pb.AppendHexDecoded([]byte("00ABff")))
+	assert.Eq(t, []byte{0x00, 0xab, 0xff}, pb.Bytes())
+	assert.Err(t, pb.AppendHexDecoded([]byte("0g")))
+	assert.Eq(t, 3, pb.Len())
+
+	pb.SetMaxSize(4)
+	assert.Equal(t, ErrTooLarge, pb.AppendHexDecoded([]byte("0102")))
+	assert.PanicsErrMsg(t, func() { pb.AppendHex([]byte{1}) }, ErrTooLarge.Error())
+}
+
+func TestBase64Writer(t *testing.T) {
+	src := bytes.Repeat([]byte("0123456789abcdefghij"), 700)
+	for _, chunk := range []int{1, 2, 7, 4096, len(src)} {
+		var out bytes.Buffer
+		w := NewBase64Writer(base64.StdEncoding, &out)
+		for p := src; len(p) > 0; {
+			k := min(chunk, len(p))
+			n, err := w.Write(p[:k])
+			assert.NoErr(t, err)
+			assert.Eq(t, k, n)
+			p = p[k:]
+		}
+		assert.NoErr(t, w.Close())
+		assert.Eq(t, base64.StdEncoding.EncodeToString(src), out.String())
+		assert.NoErr(t, w.Close())
+		_, err := w.Write(src)
+		assert.Err(t, err)
+	}
+}
+
+func TestHexWriter(t *testing.T) {
+	src := bytes.Repeat([]byte{0xde, 0xad, 0xbe, 0xef}, 3000)
+	var out bytes.Buffer
+	w := NewHexWriter(&out)
+	n, err := w.Write(src)
+	assert.NoErr(t, err)
+	assert.Eq(t, len(src), n)
+	assert.NoErr(t, w.Close())
+	assert.Eq(t, hex.EncodeToString(src), out.String())
+}
+
+type failWriter struct{}
+
+func (failWriter) Write(p []byte) (int, error) { return 0, errors.New("fail") }
+
+func TestEncoderWriterError(t *testing.T) {
+	w := NewBase64Writer(base64.StdEncoding, failWriter{})
+	_, err := w.Write([]byte("abcdef"))
+	assert.Err(t, err)
+	_, err = w.Write([]byte("abcdef"))
+	assert.Err(t, err)
+	assert.Err(t, w.Close())
+
+	hw := NewHexWriter(failWriter{})
+	_, err = hw.Write([]byte("ab"))
+	assert.Err(t, err)
+	assert.Err(t, hw.Close())
+}
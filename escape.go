@@ -0,0 +1,117 @@
+package bpool
+
+// The escaping appenders write the escaped form of a string straight into
+// ByteBuffer.B instead of returning a new string the way html.EscapeString or
+// url.QueryEscape do. See format.go for how the Append methods grow b and
+// handle the size limit.
+
+// AppendHTMLEscaped appends s with the characters <, >, &, ' and " escaped,
+// as html.EscapeString does.
+func (b *Bytes) AppendHTMLEscaped(s string) {
+	b.mustGrow(len(s))
+	defer b.truncateOnPanic(b.Len())
+	start := 0
+	for i := 0; i < len(s); i++ {
+		var esc string
+		switch s[i] {
+		case '<':
+			esc = "&lt;"
+		case '>':
+			esc = "&gt;"
+		case '&':
+			esc = "&amp;"
+		case '\'':
+			esc = "&#39;"
+		case '"':
+			esc = "&#34;"
+		default:
+			continue
+		}
+		b.mustWriteString(s[start:i])
+		b.mustWriteString(esc)
+		start = i + 1
+	}
+	b.mustWriteString(s[start:])
+}
+
+// AppendURLQueryEscaped appends s escaped for use in a URL query, as
+// url.QueryEscape does: spaces become '+'.
+func (b *Bytes) AppendURLQueryEscaped(s string) {
+	b.appendURLEscaped(s, false)
+}
+
+// AppendPathEscaped appends s escaped for use as a URL path segment, as
+// url.PathEscape does.
+func (b *Bytes) AppendPathEscaped(s string) {
+	b.appendURLEscaped(s, true)
+}
+
+func (b *Bytes) appendURLEscaped(s string, path bool) {
+	b.mustGrow(len(s))
+	defer b.truncateOnPanic(b.Len())
+	start := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !shouldEscapeURL(c, path) {
+			continue
+		}
+		b.mustWriteString(s[start:i])
+		if c == ' ' && !path {
+			b.mustWriteString("+")
+		} else {
+			b.mustWrite([]byte{'%', upperHexDigits[c>>4], upperHexDigits[c&0xf]})
+		}
+		start = i + 1
+	}
+	b.mustWriteString(s[start:])
+}
+
+// shouldEscapeURL follows the rules of net/url for query components and, if
+// path is set, path segments.
+func shouldEscapeURL(c byte, path bool) bool {
+	if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' {
+		return false
+	}
+	switch c {
+	case '-', '_', '.', '~':
+		return false
+	case '$', '&', '+', ':', '=', '@':
+		return !path
+	}
+	return true
+}
+
+// AppendQuotedCSVField appends field as a double-quoted CSV field, doubling
+// the quotes inside it. The result is valid whatever the delimiter, and may
+// span lines if field does. encoding/csv reads it back as field, except that
+// each \r\n inside it becomes \n.
+func (b *Bytes) AppendQuotedCSVField(field string) {
+	b.appendQuoted(field, '"', `""`)
+}
+
+// AppendShellQuoted appends s single-quoted for a POSIX shell, so that the
+// shell reads it back as one word with no expansion. Each single quote inside
+// s closes the quoting, adds an escaped quote and reopens it.
+func (b *Bytes) AppendShellQuoted(s string) {
+	b.appendQuoted(s, '\'', `'\''`)
+}
+
+// appendQuoted appends s between two quote bytes, replacing each quote inside
+// s with esc.
+func (b *Bytes) appendQuoted(s string, quote byte, esc string) {
+	b.mustGrow(len(s) + 2)
+	defer b.truncateOnPanic(b.Len())
+	b.B = append(b.B, quote)
+	start := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == quote {
+			b.mustWriteString(s[start:i])
+			b.mustWriteString(esc)
+			start = i + 1
+		}
+	}
+	b.mustWriteString(s[start:])
+	b.mustWrite([]byte{quote})
+}
+
+const upperHexDigits = "0123456789ABCDEF"
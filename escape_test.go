@@ -0,0 +1,104 @@
+package bpool
+
+import (
+	"encoding/csv"
+	"html"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+var escapeInputs = []string{
+	"",
+	"plain-text_1.2~",
+	`<a href="x?y=1&z='2'">`,
+	"spaces and\ttabs\nnewlines",
+	"reserved $&+,/:;=?@#[]!*()%",
+	"utf-8 héllo 世界",
+	"\x00\x7f\xff",
+}
+
+func TestBytes_AppendHTMLEscaped(t *testing.T) {
+	for _, s := range escapeInputs {
+		pb := Get(0)
+		pb.AppendHTMLEscaped(s)
+		assert.Eq(t, html.EscapeString(s), pb.String())
+		pb.Release()
+	}
+}
+
+func TestBytes_AppendURLEscaped(t *testing.T) {
+	for _, s := range escapeInputs {
+		pb := Get(0)
+		pb.AppendURLQueryEscaped(s)
+		assert.Eq(t, url.QueryEscape(s), pb.String())
+		pb.Reset()
+		pb.AppendPathEscaped(s)
+		assert.Eq(t, url.PathEscape(s), pb.String())
+		pb.Release()
+	}
+}
+
+func TestBytes_AppendQuotedCSVField(t *testing.T) {
+	pb := Get(0)
+	defer pb.Release()
+	for i, s := range escapeInputs[:6] {
+		if i > 0 {
+			pb.WriteByte(';')
+		}
+		pb.AppendQuotedCSVField(s)
+	}
+	r := csv.NewReader(strings.NewReader(pb.String()))
+	r.Comma = ';'
+	records, err := r.ReadAll()
+	assert.NoErr(t, err)
+	assert.Len(t, records, 1)
+	assert.Eq(t, escapeInputs[:6], records[0])
+}
+
+func TestBytes_AppendShellQuoted(t *testing.T) {
+	pb := Get(0)
+	defer pb.Release()
+	pb.AppendShellQuoted("it's $HOME")
+	pb.WriteByte(' ')
+	pb.AppendShellQuoted("")
+	assert.Eq(t, `'it'\''s $HOME' ''`, pb.String())
+}
+
+func TestBytes_AppendEscapedTooLarge(t *testing.T) {
+	pb := Get(64)
+	defer pb.Release()
+	pb.SetMaxSize(12)
+	pb.WriteString("x=")
+	// Each input fits the limit unescaped and overflows it halfway through
+	// the escapes.
+	for _, f := range []func(){
+		func() { pb.AppendHTMLEscaped("a<b>c<d>") },
+		func() { pb.AppendURLQueryEscaped("a b&c=d?") },
+		func() { pb.AppendPathEscaped("a/b/c/d/") },
+		func() { pb.AppendQuotedCSVField(`a"b"c"d"`) },
+		func() { pb.AppendShellQuoted("a'b'c'd") },
+	} {
+		assert.PanicsErrMsg(t, f, ErrTooLarge.Error())
+		assert.Eq(t, "x=", pb.String())
+	}
+	pb.AppendHTMLEscaped("a<b")
+	assert.Eq(t, "x=a&lt;b", pb.String())
+}
+
+func TestBytes_AppendEscapedAllocs(t *testing.T) {
+	pb := Get(1024)
+	defer pb.Release()
+	s := escapeInputs[2] + escapeInputs[4]
+	allocs := testing.AllocsPerRun(100, func() {
+		pb.Reset()
+		pb.AppendHTMLEscaped(s)
+		pb.AppendURLQueryEscaped(s)
+		pb.AppendPathEscaped(s)
+		pb.AppendQuotedCSVField(s)
+		pb.AppendShellQuoted(s)
+	})
+	assert.Eq(t, float64(0), allocs)
+}
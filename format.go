@@ -0,0 +1,121 @@
+package bpool
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// The Append methods, here and in escape.go, json.go and binary.go, format a
+// value straight into ByteBuffer.B, the way the strconv Append functions do,
+// and grow b through its Allocator. Having no error result, they panic with
+// ErrTooLarge if the value would exceed the limit set with SetMaxSize;
+// nothing is appended in that case.
+
+// AppendInt appends the string form of i in the given base, as
+// strconv.AppendInt does.
+func (b *Bytes) AppendInt(i int64, base int) {
+	var scratch [64 + 1]byte
+	b.mustWrite(strconv.AppendInt(scratch[:0], i, base))
+}
+
+// AppendUint appends the string form of i in the given base, as
+// strconv.AppendUint does.
+func (b *Bytes) AppendUint(i uint64, base int) {
+	var scratch [64]byte
+	b.mustWrite(strconv.AppendUint(scratch[:0], i, base))
+}
+
+// AppendFloat appends the string form of f, as strconv.AppendFloat does.
+func (b *Bytes) AppendFloat(f float64, format byte, prec, bitSize int) {
+	var scratch [64]byte
+	b.mustWrite(strconv.AppendFloat(scratch[:0], f, format, prec, bitSize))
+}
+
+// AppendBool appends "true" or "false" according to v.
+func (b *Bytes) AppendBool(v bool) {
+	if v {
+		b.mustWriteString("true")
+	} else {
+		b.mustWriteString("false")
+	}
+}
+
+// AppendQuote appends a double-quoted Go string literal representing s, as
+// strconv.AppendQuote does.
+func (b *Bytes) AppendQuote(s string) {
+	// Escaping turns a byte into at most four, so 4*len(s)+2 bytes always
+	// hold the result. Short strings are quoted on the stack; long ones get
+	// that much room from the Allocator first, up to the limit, so strconv
+	// only reallocates for a result that is too large anyway.
+	n := 4*len(s) + 2
+	if n <= 128 {
+		var scratch [128]byte
+		b.mustWrite(strconv.AppendQuote(scratch[:0], s))
+		return
+	}
+	b.checkLive("AppendQuote")
+	b.lastRead = opInvalid
+	if b.tooLarge(len(s) + 2) {
+		panic(ErrTooLarge)
+	}
+	b.grow(b.growSize(b.Len()+n) - b.Len())
+	b.checkAppended(strconv.AppendQuote(b.B, s))
+}
+
+// AppendTime appends the textual representation of t formatted according to
+// layout, as time.Time.AppendFormat does.
+func (b *Bytes) AppendTime(t time.Time, layout string) {
+	var scratch [64]byte
+	b.mustWrite(t.AppendFormat(scratch[:0], layout))
+}
+
+// Printf formats according to a format specifier and appends the result, as
+// fmt.Fprintf does with b as the writer. Unlike the Append methods it
+// returns ErrTooLarge instead of panicking.
+func (b *Bytes) Printf(format string, a ...any) (n int, err error) {
+	return fmt.Fprintf(b, format, a...)
+}
+
+// mustGrow is Grow for the methods without an error result.
+func (b *Bytes) mustGrow(n int) {
+	if err := b.Grow(n); err != nil {
+		panic(err)
+	}
+}
+
+// mustWrite is Write for the methods without an error result.
+func (b *Bytes) mustWrite(p []byte) {
+	if _, err := b.Write(p); err != nil {
+		panic(err)
+	}
+}
+
+// mustWriteString is WriteString for the methods without an error result.
+func (b *Bytes) mustWriteString(s string) {
+	if _, err := b.WriteString(s); err != nil {
+		panic(err)
+	}
+}
+
+// checkAppended sets ByteBuffer.B to p, the result of appending a value to
+// it in place. If p exceeds the limit it panics with ErrTooLarge instead and
+// leaves b as it was, keeping its buffer even if the append reallocated.
+func (b *Bytes) checkAppended(p []byte) {
+	if b.maxSize > 0 && len(p)-b.off > b.maxSize {
+		panic(ErrTooLarge)
+	}
+	b.B = p
+}
+
+// truncateOnPanic is deferred by the Append methods that write a value in
+// several pieces. If one of the writes panics with ErrTooLarge, it truncates
+// b back to old, the unread length before the value, and panics again, so
+// that nothing is appended. The unread length is kept rather than len(B)
+// because a write may drop the bytes already read.
+func (b *Bytes) truncateOnPanic(old int) {
+	if r := recover(); r != nil {
+		b.B = b.B[:b.off+old]
+		panic(r)
+	}
+}
@@ -0,0 +1,131 @@
+package bpool
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestBytes_Append(t *testing.T) {
+	pb := Get(0)
+	defer pb.Release()
+	pb.AppendInt(-42, 10)
+	pb.WriteByte(' ')
+	pb.AppendInt(math.MinInt64, 2)
+	pb.WriteByte(' ')
+	pb.AppendUint(math.MaxUint64, 16)
+	pb.WriteByte(' ')
+	pb.AppendFloat(3.25, 'f', -1, 64)
+	pb.WriteByte(' ')
+	pb.AppendBool(true)
+	pb.WriteByte(' ')
+	pb.AppendBool(false)
+	pb.WriteByte(' ')
+	pb.AppendQuote("a\"b\n")
+	pb.WriteByte(' ')
+	pb.AppendTime(time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC), time.RFC3339)
+	assert.Eq(t, "-42 "+strconv.FormatInt(math.MinInt64, 2)+
+		" ffffffffffffffff 3.25 true false \"a\\\"b\\n\" 2024-05-06T07:08:09Z", pb.String())
+}
+
+func TestBytes_Printf(t *testing.T) {
+	pb := Get(8)
+	defer pb.Release()
+	n, err := pb.Printf("%s=%d", "answer", 42)
+	assert.NoErr(t, err)
+	assert.Eq(t, 9, n)
+	assert.Eq(t, "answer=42", pb.String())
+
+	pb.SetMaxSize(12)
+	_, err = pb.Printf("%s", "overflow")
+	assert.Equal(t, ErrTooLarge, err)
+	assert.Eq(t, "answer=42", pb.String())
+}
+
+func TestBytes_AppendTooLarge(t *testing.T) {
+	pb := Get(64)
+	defer pb.Release()
+	pb.SetMaxSize(9)
+	pb.AppendInt(12345, 10)
+	assert.PanicsErrMsg(t, func() { pb.AppendInt(123456, 10) }, ErrTooLarge.Error())
+	assert.PanicsErrMsg(t, func() { pb.AppendBool(false) }, ErrTooLarge.Error())
+	// The limit applies to the escaped string, not to s.
+	assert.PanicsErrMsg(t, func() { pb.AppendQuote("\n\n") }, ErrTooLarge.Error())
+	assert.PanicsErrMsg(t, func() { pb.AppendQuote(strings.Repeat("\n", 100)) }, ErrTooLarge.Error())
+	assert.Eq(t, "12345", pb.String())
+	pb.AppendBool(true)
+	assert.Eq(t, "12345true", pb.String())
+}
+
+// lastGetAllocator remembers the backing array of the last buffer it handed
+// out.
+type lastGetAllocator struct {
+	Allocator
+	last *byte
+}
+
+func (la *lastGetAllocator) Get(size int) *Bytes {
+	b := la.Allocator.Get(size)
+	b.SetAllocator(la)
+	la.last = &b.B[:1][0]
+	return b
+}
+
+func TestBytes_AppendGrowsThroughAllocator(t *testing.T) {
+	la := &lastGetAllocator{Allocator: New()}
+	pb := la.Get(16)
+	defer pb.Release()
+	// Every byte of s is escaped to four, well past what len(s) suggests.
+	s := strings.Repeat("\x01", 300)
+	pb.AppendQuote(s)
+	assert.Eq(t, strconv.Quote(s), pb.String())
+	assert.True(t, &pb.B[0] == la.last)
+
+	layout := strings.Repeat(time.RFC1123Z+" ", 20)
+	now := time.Now()
+	fill := strings.Repeat("-", cap(pb.B)-8)
+	pb.Reset()
+	pb.WriteString(fill)
+	pb.AppendTime(now, layout)
+	assert.Eq(t, fill+now.Format(layout), pb.String())
+	assert.True(t, &pb.B[0] == la.last)
+}
+
+func TestBytes_AppendQuoteClampsGrowth(t *testing.T) {
+	la := &lastGetAllocator{Allocator: New()}
+	pb := la.Get(16)
+	defer pb.Release()
+	pb.SetMaxSize(1 << 20)
+	s := strings.Repeat("a", 512<<10)
+	pb.AppendQuote(s)
+	assert.Eq(t, len(s)+2, pb.Len())
+	assert.True(t, cap(pb.B) <= 1<<20)
+
+	// Too large once escaped: b keeps its content and its pooled buffer.
+	pb.Reset()
+	pb.WriteString("x")
+	assert.PanicsErrMsg(t, func() { pb.AppendQuote(strings.Repeat("\x01", 300<<10)) }, ErrTooLarge.Error())
+	assert.Eq(t, "x", pb.String())
+	assert.True(t, cap(pb.B) <= 1<<20)
+	assert.True(t, &pb.B[0] == la.last)
+}
+
+func TestBytes_AppendAllocs(t *testing.T) {
+	pb := Get(256)
+	defer pb.Release()
+	now := time.Now()
+	allocs := testing.AllocsPerRun(100, func() {
+		pb.Reset()
+		pb.AppendInt(-1234567, 10)
+		pb.AppendUint(7654321, 16)
+		pb.AppendFloat(math.Pi, 'g', -1, 64)
+		pb.AppendBool(true)
+		pb.AppendQuote("quoted")
+		pb.AppendTime(now, time.RFC3339Nano)
+	})
+	assert.Eq(t, float64(0), allocs)
+}
@@ -0,0 +1,158 @@
+package bpool
+
+import (
+	"sort"
+	"sync/atomic"
+)
+
+const (
+	// histBigSteps is the number of histogram bins, and of big-size lookup
+	// entries of a class table, per power of two above _MaxSmallSize.
+	histBigSteps     = 16
+	histBigStepsBits = 4
+	histMaxPower     = 31
+)
+
+// WithSizeHistogram makes Get record the sizes it is asked for, see
+// BytesPool.SizeHistogram and BytesPool.Adapt. Sizes up to 32 KB are
+// recorded with a granularity of 8 bytes, larger ones with 16 bins per power
+// of two. Recording costs an atomic add per Get.
+func WithSizeHistogram() Option {
+	return func(c *poolConfig) {
+		c.histogram = true
+	}
+}
+
+type sizeHistogram struct {
+	small [_MaxSmallSize / smallSizeDiv]atomic.Uint64
+	big   [(histMaxPower - _MaxSamllSizePower) * histBigSteps]atomic.Uint64
+}
+
+func (h *sizeHistogram) record(size int) {
+	if size <= 0 || size > _MaxClassSize {
+		return
+	}
+	if size <= _MaxSmallSize {
+		h.small[divRoundUp(uintptr(size), smallSizeDiv)-1].Add(1)
+		return
+	}
+	h.big[bigBin(size)].Add(1)
+}
+
+// snapshot returns the non-empty bins keyed by their largest size.
+func (h *sizeHistogram) snapshot() (counts map[int]uint64) {
+	counts = make(map[int]uint64)
+	for i := range h.small {
+		if n := h.small[i].Load(); n > 0 {
+			counts[(i+1)*smallSizeDiv] = n
+		}
+	}
+	for i := range h.big {
+		if n := h.big[i].Load(); n > 0 {
+			bit := i/histBigSteps + _MaxSamllSizePower + 1
+			// The last bin ends at 1<<31, which does not fit an int on
+			// 32-bit platforms; no size recorded in it is above
+			// _MaxClassSize.
+			size := uint64(1)<<(bit-1) + uint64(i%histBigSteps+1)<<(bit-1-histBigStepsBits)
+			counts[int(min(size, _MaxClassSize))] = n
+		}
+	}
+	return
+}
+
+// SizeHistogram returns how often Get was asked for each size since bp was
+// created. Sizes are rounded up to the bin they were recorded in, see
+// WithSizeHistogram. It returns nil unless bp was created with
+// WithSizeHistogram.
+func (bp *BytesPool) SizeHistogram() map[int]uint64 {
+	if bp.histogram == nil {
+		return nil
+	}
+	return bp.histogram.snapshot()
+}
+
+// Adapt replaces the size classes of bp with the n classes RecommendClasses
+// derives from its size histogram; see SetClasses. It does nothing if no
+// sizes were recorded.
+func (bp *BytesPool) Adapt(n int) (err error) {
+	classes := RecommendClasses(bp.SizeHistogram(), n)
+	if len(classes) == 0 {
+		return
+	}
+	return bp.SetClasses(classes)
+}
+
+// RecommendClasses returns at most n class sizes that minimize the internal
+// fragmentation, i.e. the bytes allocated but not asked for, of a workload
+// that requested each size in counts the given number of times. Every class
+// is one of the requested sizes, and the largest requested size is always a
+// class. For counts returned by SizeHistogram, the result can always be
+// passed to NewWithClasses or SetClasses.
+func RecommendClasses(counts map[int]uint64, n int) (classes []int) {
+	sizes := make([]int, 0, len(counts))
+	for size, count := range counts {
+		if size > 0 && size <= _MaxClassSize && count > 0 {
+			sizes = append(sizes, size)
+		}
+	}
+	sort.Ints(sizes)
+	n = min(n, 255)
+	if n <= 0 {
+		return nil
+	}
+	if len(sizes) <= n {
+		return sizes
+	}
+	m := len(sizes)
+	// weights[i] and bytes[i] sum the counts and requested bytes of the
+	// sizes before i, so that the waste of serving sizes[j..i] from a class
+	// of sizes[i] is available in constant time.
+	weights := make([]float64, m+1)
+	bytes := make([]float64, m+1)
+	for i, size := range sizes {
+		w := float64(counts[size])
+		weights[i+1] = weights[i] + w
+		bytes[i+1] = bytes[i] + w*float64(size)
+	}
+	waste := func(j, i int) float64 {
+		return float64(sizes[i])*(weights[i+1]-weights[j]) - (bytes[i+1] - bytes[j])
+	}
+	// best[i] is the least waste of serving sizes[0..i] with k classes, the
+	// largest being sizes[i]; first[k][i] is where the group of that class
+	// starts. The optimal start is monotone in i, which lets every round be
+	// solved by divide and conquer in O(m log m).
+	best := make([]float64, m)
+	next := make([]float64, m)
+	first := make([][]int32, n)
+	first[0] = make([]int32, m)
+	for i := range best {
+		best[i] = waste(0, i)
+	}
+	var solve func(k, lo, hi, optLo, optHi int)
+	solve = func(k, lo, hi, optLo, optHi int) {
+		if lo > hi {
+			return
+		}
+		mid := (lo + hi) / 2
+		bestJ, bestCost := -1, 0.0
+		for j := max(optLo, k); j <= min(mid, optHi); j++ {
+			if c := best[j-1] + waste(j, mid); bestJ < 0 || c < bestCost {
+				bestJ, bestCost = j, c
+			}
+		}
+		next[mid], first[k][mid] = bestCost, int32(bestJ)
+		solve(k, lo, mid-1, optLo, bestJ)
+		solve(k, mid+1, hi, bestJ, optHi)
+	}
+	for k := 1; k < n; k++ {
+		first[k] = make([]int32, m)
+		solve(k, k, m-1, k, m-1)
+		best, next = next, best
+	}
+	classes = make([]int, n)
+	for k, i := n-1, m-1; k >= 0; k-- {
+		classes[k] = sizes[i]
+		i = int(first[k][i]) - 1
+	}
+	return
+}
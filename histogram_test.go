@@ -0,0 +1,95 @@
+package bpool
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestSizeHistogram(t *testing.T) {
+	bp := New(WithSizeHistogram())
+	for _, size := range []int{0, 1, 8, 9, 1400, 1400, 40000, 1 << 16, 1<<16 + 1, 9 << 20} {
+		bp.Get(size)
+	}
+	assert.Eq(t, map[int]uint64{
+		8:               2,
+		16:              1,
+		1400:            2,
+		40960:           1,
+		1 << 16:         1,
+		1<<16 + 1<<12:   1,
+		8<<20 + 1<<19*2: 1,
+	}, bp.SizeHistogram())
+	assert.Nil(t, New().SizeHistogram())
+}
+
+// bruteWaste returns the least waste of serving sizes with n classes.
+func bruteWaste(sizes []int, counts map[int]uint64, n int) (best uint64) {
+	best = ^uint64(0)
+	m := len(sizes)
+	for mask := 0; mask < 1<<(m-1); mask++ {
+		classes := []int{}
+		for i := 0; i < m-1; i++ {
+			if mask&(1<<i) != 0 {
+				classes = append(classes, sizes[i])
+			}
+		}
+		classes = append(classes, sizes[m-1])
+		if len(classes) > n {
+			continue
+		}
+		best = min(best, classWaste(classes, counts))
+	}
+	return
+}
+
+func classWaste(classes []int, counts map[int]uint64) (waste uint64) {
+	for size, count := range counts {
+		i := slices.IndexFunc(classes, func(c int) bool { return c >= size })
+		waste += uint64(classes[i]-size) * count
+	}
+	return
+}
+
+func TestRecommendClasses(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for round := 0; round < 50; round++ {
+		counts := make(map[int]uint64)
+		for i := 0; i < 2+r.Intn(10); i++ {
+			counts[1+r.Intn(5000)] = uint64(1 + r.Intn(100))
+		}
+		var sizes []int
+		for size := range counts {
+			sizes = append(sizes, size)
+		}
+		slices.Sort(sizes)
+		n := 1 + r.Intn(len(sizes))
+		classes := RecommendClasses(counts, n)
+		assert.True(t, len(classes) <= n)
+		assert.Eq(t, sizes[len(sizes)-1], classes[len(classes)-1])
+		assert.Eq(t, bruteWaste(sizes, counts, n), classWaste(classes, counts))
+	}
+	assert.Nil(t, RecommendClasses(map[int]uint64{10: 1}, 0))
+}
+
+func TestAdapt(t *testing.T) {
+	bp := New(WithSizeHistogram(), WithStats())
+	for i := 0; i < 1000; i++ {
+		bp.Get(1400)
+		bp.Get(60)
+	}
+	bp.Get(3000)
+	old := bp.Get(1400)
+	assert.NoErr(t, bp.Adapt(2))
+	assert.Eq(t, []int{1400, 3000}, bp.Classes())
+	assert.Eq(t, 1400, cap(bp.Get(1400).B))
+	assert.Eq(t, 1400, cap(bp.Get(100).B))
+	// A buffer of the old table is demoted into the new one.
+	old.Release()
+	assert.Eq(t, uint64(1), bp.Stats().Classes[0].Puts)
+
+	assert.NoErr(t, New().Adapt(10))
+	assert.Err(t, bp.SetClasses([]int{10, 5}))
+}
@@ -0,0 +1,242 @@
+package bpool
+
+import (
+	"math"
+	"strconv"
+	"unicode/utf8"
+)
+
+// The AppendJSON methods append a single JSON value to ByteBuffer.B without
+// any separator; JSONWriter puts them together into objects and arrays.
+// Growth and the size limit work as for the other Append methods, see
+// format.go.
+
+// AppendJSONString appends s as a quoted JSON string. Invalid UTF-8 is
+// replaced by U+FFFD, and U+2028 and U+2029 are escaped so that the result is
+// also valid JavaScript. Unlike encoding/json, <, > and & are not escaped.
+func (b *Bytes) AppendJSONString(s string) {
+	b.mustGrow(len(s) + 2)
+	defer b.truncateOnPanic(b.Len())
+	b.B = append(b.B, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' {
+				i++
+				continue
+			}
+			b.mustWriteString(s[start:i])
+			switch c {
+			case '"', '\\':
+				b.mustWrite([]byte{'\\', c})
+			case '\b':
+				b.mustWriteString(`\b`)
+			case '\f':
+				b.mustWriteString(`\f`)
+			case '\n':
+				b.mustWriteString(`\n`)
+			case '\r':
+				b.mustWriteString(`\r`)
+			case '\t':
+				b.mustWriteString(`\t`)
+			default:
+				b.mustWrite([]byte{'\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xf]})
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			b.mustWriteString(s[start:i])
+			b.mustWriteString("\ufffd")
+			i += size
+			start = i
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' {
+			b.mustWriteString(s[start:i])
+			b.mustWrite([]byte{'\\', 'u', '2', '0', '2', hexDigits[r&0xf]})
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	b.mustWriteString(s[start:])
+	b.mustWriteString(`"`)
+}
+
+// AppendJSONInt appends i as a JSON number.
+func (b *Bytes) AppendJSONInt(i int64) {
+	b.AppendInt(i, 10)
+}
+
+// AppendJSONUint appends i as a JSON number.
+func (b *Bytes) AppendJSONUint(i uint64) {
+	b.AppendUint(i, 10)
+}
+
+// AppendJSONFloat appends f as a JSON number formatted the way encoding/json
+// does for a float of the given bitSize (32 or 64). JSON has no NaN or
+// infinities; they are appended as null.
+func (b *Bytes) AppendJSONFloat(f float64, bitSize int) {
+	if bitSize == 32 {
+		f = float64(float32(f))
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		b.mustWriteString("null")
+		return
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 {
+		if bitSize == 64 && (abs < 1e-6 || abs >= 1e21) ||
+			bitSize == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	var scratch [64]byte
+	p := strconv.AppendFloat(scratch[:0], f, format, -1, bitSize)
+	if format == 'e' {
+		// Clean up e-09 to e-9, as encoding/json does.
+		if n := len(p); n >= 4 && p[n-4] == 'e' && p[n-3] == '-' && p[n-2] == '0' {
+			p[n-2] = p[n-1]
+			p = p[:n-1]
+		}
+	}
+	b.mustWrite(p)
+}
+
+// AppendJSONBool appends true or false.
+func (b *Bytes) AppendJSONBool(v bool) {
+	b.AppendBool(v)
+}
+
+// AppendJSONNull appends null.
+func (b *Bytes) AppendJSONNull() {
+	b.mustWriteString("null")
+}
+
+// AppendJSONRaw appends raw, an already encoded JSON value, as is. It is not
+// validated.
+func (b *Bytes) AppendJSONRaw(raw []byte) {
+	b.mustWrite(raw)
+}
+
+// JSONWriter builds a JSON document at the end of a Bytes, inserting the
+// commas between object members and array elements. It is a small value with
+// no state besides where the document starts, so it costs no allocation:
+//
+//	j := b.JSON()
+//	j.BeginObject()
+//	j.Key("id")
+//	j.Int(42)
+//	j.Key("tags")
+//	j.BeginArray()
+//	j.String("a")
+//	j.String("b")
+//	j.EndArray()
+//	j.EndObject() // {"id":42,"tags":["a","b"]}
+//
+// A comma is written before a value or key unless the document is empty or
+// the previous byte opened an object or array or ended a key; the caller is
+// responsible for calling Key before each member of an object.
+type JSONWriter struct {
+	b *Bytes
+	// start is where the document starts in the unread portion of b; a
+	// write that grows b may drop the bytes already read.
+	start int
+}
+
+// JSON returns a JSONWriter for a document appended to b from its current
+// end.
+func (b *Bytes) JSON() JSONWriter {
+	return JSONWriter{b: b, start: b.Len()}
+}
+
+// sep writes the comma needed before the next key or value.
+func (j JSONWriter) sep() {
+	b := j.b
+	if b.Len() <= j.start {
+		return
+	}
+	switch b.B[len(b.B)-1] {
+	case '{', '[', ':', ',':
+		return
+	}
+	b.mustWriteString(",")
+}
+
+// BeginObject opens an object.
+func (j JSONWriter) BeginObject() {
+	j.sep()
+	j.b.mustWriteString("{")
+}
+
+// EndObject closes the innermost object.
+func (j JSONWriter) EndObject() {
+	j.b.mustWriteString("}")
+}
+
+// BeginArray opens an array.
+func (j JSONWriter) BeginArray() {
+	j.sep()
+	j.b.mustWriteString("[")
+}
+
+// EndArray closes the innermost array.
+func (j JSONWriter) EndArray() {
+	j.b.mustWriteString("]")
+}
+
+// Key writes the key of the next object member.
+func (j JSONWriter) Key(k string) {
+	j.sep()
+	j.b.AppendJSONString(k)
+	j.b.mustWriteString(":")
+}
+
+// String writes s as a JSON string value.
+func (j JSONWriter) String(s string) {
+	j.sep()
+	j.b.AppendJSONString(s)
+}
+
+// Int writes i as a number value.
+func (j JSONWriter) Int(i int64) {
+	j.sep()
+	j.b.AppendJSONInt(i)
+}
+
+// Uint writes i as a number value.
+func (j JSONWriter) Uint(i uint64) {
+	j.sep()
+	j.b.AppendJSONUint(i)
+}
+
+// Float writes f as a number value; see AppendJSONFloat.
+func (j JSONWriter) Float(f float64) {
+	j.sep()
+	j.b.AppendJSONFloat(f, 64)
+}
+
+// Bool writes v as a boolean value.
+func (j JSONWriter) Bool(v bool) {
+	j.sep()
+	j.b.AppendJSONBool(v)
+}
+
+// Null writes a null value.
+func (j JSONWriter) Null() {
+	j.sep()
+	j.b.AppendJSONNull()
+}
+
+// Raw writes raw, an already encoded JSON value, as is.
+func (j JSONWriter) Raw(raw []byte) {
+	j.sep()
+	j.b.AppendJSONRaw(raw)
+}
+
+const hexDigits = "0123456789abcdef"
@@ -0,0 +1,126 @@
+package bpool
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+// stdJSON encodes v with encoding/json, without HTML escaping.
+func stdJSON(t *testing.T, v any) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	assert.NoErr(t, enc.Encode(v))
+	return string(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+}
+
+func TestBytes_AppendJSONString(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"plain",
+		`quote " and backslash \`,
+		"controls \x00\x01\b\f\n\r\t\x1f\x7f",
+		"<html> & more",
+		"utf-8 héllo 世界 🙂",
+		"line\u2028para\u2029",
+		"bad \xff utf-8 \xe2\x82",
+	} {
+		pb := Get(0)
+		pb.AppendJSONString(s)
+		assert.Eq(t, stdJSON(t, s), pb.String(), s)
+		pb.Release()
+	}
+}
+
+func TestBytes_AppendJSONStringTooLarge(t *testing.T) {
+	pb := Get(64)
+	defer pb.Release()
+	pb.SetMaxSize(12)
+	pb.WriteString("[")
+	// The escapes overflow the limit halfway through the string.
+	assert.PanicsErrMsg(t, func() { pb.AppendJSONString("ab\n\n\n\n\n\n") }, ErrTooLarge.Error())
+	assert.Eq(t, "[", pb.String())
+	pb.AppendJSONString("ab\n")
+	assert.Eq(t, `["ab\n"`, pb.String())
+}
+
+func TestBytes_AppendJSONFloat(t *testing.T) {
+	for _, f := range []float64{0, 1, -1.5, 1e-7, 123456789, 1e20, 1e21, 3.14159e-10, math.MaxFloat32} {
+		pb := Get(0)
+		pb.AppendJSONFloat(f, 64)
+		assert.Eq(t, stdJSON(t, f), pb.String())
+		pb.Reset()
+		pb.AppendJSONFloat(f, 32)
+		assert.Eq(t, stdJSON(t, float32(f)), pb.String())
+		pb.Release()
+	}
+	pb := Get(0)
+	defer pb.Release()
+	pb.AppendJSONFloat(math.NaN(), 64)
+	pb.AppendJSONFloat(math.Inf(-1), 64)
+	pb.AppendJSONFloat(math.MaxFloat64, 32)
+	assert.Eq(t, "nullnullnull", pb.String())
+}
+
+func TestJSONWriter(t *testing.T) {
+	pb := Get(0)
+	defer pb.Release()
+	pb.WriteString("data: ")
+	j := pb.JSON()
+	j.BeginObject()
+	j.Key("id")
+	j.Int(-42)
+	j.Key("size")
+	j.Uint(7)
+	j.Key("ratio")
+	j.Float(0.5)
+	j.Key("ok")
+	j.Bool(true)
+	j.Key("none")
+	j.Null()
+	j.Key("empty")
+	j.BeginObject()
+	j.EndObject()
+	j.Key("list")
+	j.BeginArray()
+	j.String("a")
+	j.BeginArray()
+	j.EndArray()
+	j.Raw([]byte(`{"x":1}`))
+	j.BeginObject()
+	j.Key("k")
+	j.String("v")
+	j.EndObject()
+	j.EndArray()
+	j.EndObject()
+	doc := `{"id":-42,"size":7,"ratio":0.5,"ok":true,"none":null,"empty":{},` +
+		`"list":["a",[],{"x":1},{"k":"v"}]}`
+	assert.Eq(t, "data: "+doc, pb.String())
+	assert.True(t, json.Valid(pb.Bytes()[len("data: "):]))
+
+	// Top-level values in a fresh document get no leading comma.
+	pb.Reset()
+	j = pb.JSON()
+	j.String("only")
+	assert.Eq(t, `"only"`, pb.String())
+}
+
+func TestJSONWriter_Allocs(t *testing.T) {
+	pb := Get(256)
+	defer pb.Release()
+	allocs := testing.AllocsPerRun(100, func() {
+		pb.Reset()
+		j := pb.JSON()
+		j.BeginObject()
+		j.Key("msg")
+		j.String("escaped \"text\"\n\x01 ")
+		j.Key("n")
+		j.Float(1e-9)
+		j.EndObject()
+	})
+	assert.Eq(t, float64(0), allocs)
+}
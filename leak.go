@@ -0,0 +1,121 @@
+package bpool
+
+import (
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// WithLeakTracking makes the pool remember every *Bytes handed out by Get,
+// together with the stack of the Get call, until it is given back by Put or
+// Release. Outstanding and DumpLeaks report the buffers that are still out.
+//
+// Tracked buffers are referenced by the pool and never garbage collected
+// while outstanding, and every Get and Put takes a lock, so tracking is meant
+// for tests and diagnostics.
+func WithLeakTracking() Option {
+	return func(c *poolConfig) {
+		c.tracking = true
+	}
+}
+
+// OutstandingBuffer describes a *Bytes obtained from Get and not yet put
+// back.
+type OutstandingBuffer struct {
+	Bytes *Bytes
+	// Size is the size passed to Get.
+	Size int
+	// ClassSize is the size of the class the buffer came from, 0 if Size was
+	// served by a direct allocation.
+	ClassSize int
+	// Stack holds the program counters of the Get call site.
+	Stack []uintptr
+	seq   uint64
+}
+
+type leakTracker struct {
+	mu   sync.Mutex
+	seq  uint64
+	live map[*Bytes]*OutstandingBuffer
+}
+
+func (lt *leakTracker) add(b *Bytes, size, classSize int) {
+	ob := &OutstandingBuffer{Bytes: b, Size: size, ClassSize: classSize, Stack: callers(3)}
+	lt.mu.Lock()
+	lt.seq++
+	ob.seq = lt.seq
+	lt.live[b] = ob
+	lt.mu.Unlock()
+}
+
+func (lt *leakTracker) remove(b *Bytes) {
+	lt.mu.Lock()
+	delete(lt.live, b)
+	lt.mu.Unlock()
+}
+
+// Outstanding returns the buffers obtained from bp and not yet returned, in
+// the order they were obtained. It returns nil unless bp was created with
+// WithLeakTracking.
+func (bp *BytesPool) Outstanding() (obs []OutstandingBuffer) {
+	if bp.tracker == nil {
+		return
+	}
+	bp.tracker.mu.Lock()
+	obs = make([]OutstandingBuffer, 0, len(bp.tracker.live))
+	for _, ob := range bp.tracker.live {
+		obs = append(obs, *ob)
+	}
+	bp.tracker.mu.Unlock()
+	sort.Slice(obs, func(i, j int) bool {
+		return obs[i].seq < obs[j].seq
+	})
+	return
+}
+
+// DumpLeaks writes a report of the outstanding buffers of bp to w. Buffers
+// obtained at the same call site are reported together, largest total first.
+func (bp *BytesPool) DumpLeaks(w io.Writer) (err error) {
+	type site struct {
+		stack []uintptr
+		count int
+		bytes int
+	}
+	var sites []*site
+	bySite := make(map[string]*site)
+	for _, ob := range bp.Outstanding() {
+		key := stackKey(ob.Stack)
+		s := bySite[key]
+		if s == nil {
+			s = &site{stack: ob.Stack}
+			bySite[key] = s
+			sites = append(sites, s)
+		}
+		s.count++
+		s.bytes += cap(ob.Bytes.B)
+	}
+	sort.SliceStable(sites, func(i, j int) bool {
+		return sites[i].bytes > sites[j].bytes
+	})
+	sb := &strings.Builder{}
+	for _, s := range sites {
+		sb.WriteString(strconv.Itoa(s.count))
+		sb.WriteString(" outstanding buffer(s), ")
+		sb.WriteString(strconv.Itoa(s.bytes))
+		sb.WriteString(" bytes, obtained at:\n")
+		formatStack(sb, s.stack)
+	}
+	_, err = io.WriteString(w, sb.String())
+	return
+}
+
+func stackKey(pcs []uintptr) string {
+	var buf []byte
+	for _, pc := range pcs {
+		buf = strconv.AppendUint(buf, uint64(pc), 16)
+		buf = append(buf, ',')
+	}
+	return string(buf)
+}
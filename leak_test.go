@@ -0,0 +1,40 @@
+package bpool
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func leakyGet(bp *BytesPool) *Bytes {
+	return bp.Get(1000)
+}
+
+func TestLeakTracking(t *testing.T) {
+	bp := New(WithLeakTracking())
+	a := bp.Get(10)
+	b := leakyGet(bp)
+	c := bp.Get(_MaxBigSize + 1)
+	a.Release()
+	// Growing swaps buffers internally, the *Bytes must stay tracked once.
+	b.WriteString(strings.Repeat("x", 5000))
+
+	obs := bp.Outstanding()
+	assert.Len(t, obs, 2)
+	assert.Eq(t, b, obs[0].Bytes)
+	assert.Eq(t, 1000, obs[0].Size)
+	assert.Eq(t, 1024, obs[0].ClassSize)
+	assert.Eq(t, c, obs[1].Bytes)
+	assert.Eq(t, 0, obs[1].ClassSize)
+
+	sb := &strings.Builder{}
+	assert.NoErr(t, bp.DumpLeaks(sb))
+	assert.StrContains(t, sb.String(), "1 outstanding buffer(s)")
+	assert.StrContains(t, sb.String(), ".leakyGet\n")
+
+	b.Release()
+	c.Release()
+	assert.Len(t, bp.Outstanding(), 0)
+	assert.Nil(t, New().Outstanding())
+}
@@ -0,0 +1,130 @@
+package bpool
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestBytes_MaxSizeWrites(t *testing.T) {
+	pb := Get(64)
+	defer pb.Release()
+	pb.SetMaxSize(8)
+	assert.Eq(t, 8, pb.MaxSize())
+
+	n, err := pb.WriteString("12345")
+	assert.NoErr(t, err)
+	assert.Eq(t, 5, n)
+	n, err = pb.Write([]byte("6789"))
+	assert.Equal(t, ErrTooLarge, err)
+	assert.Eq(t, 0, n)
+	n, err = pb.WriteString("6789")
+	assert.Equal(t, ErrTooLarge, err)
+	assert.Eq(t, 0, n)
+	assert.Eq(t, "12345", pb.String())
+
+	assert.NoErr(t, pb.WriteByte('6'))
+	_, err = pb.WriteRune('é')
+	assert.NoErr(t, err)
+	assert.Equal(t, ErrTooLarge, pb.WriteByte('9'))
+	_, err = pb.WriteRune('é')
+	assert.Equal(t, ErrTooLarge, err)
+	assert.Equal(t, ErrTooLarge, pb.Grow(1))
+	_, err = pb.WriteAt([]byte("x"), 8)
+	assert.Equal(t, ErrTooLarge, err)
+	assert.Eq(t, "123456é", pb.String())
+
+	pb.SetMaxSize(0)
+	assert.Eq(t, 0, pb.MaxSize())
+	_, err = pb.WriteString("no limit")
+	assert.NoErr(t, err)
+}
+
+func TestBytes_MaxSizeSlowPath(t *testing.T) {
+	pb := Get(4)
+	defer pb.Release()
+	pb.SetMaxSize(100)
+	_, err := pb.Write(make([]byte, 101))
+	assert.Equal(t, ErrTooLarge, err)
+	_, err = pb.Write(make([]byte, 100))
+	assert.NoErr(t, err)
+	assert.Eq(t, 100, pb.Len())
+}
+
+func TestBytes_MaxSizeIgnoresReadBytes(t *testing.T) {
+	pb := Get(16)
+	defer pb.Release()
+	pb.SetMaxSize(1000)
+	_, err := pb.Write(make([]byte, 1000))
+	assert.NoErr(t, err)
+	pb.Next(990)
+	_, err = pb.Write(make([]byte, 500))
+	assert.NoErr(t, err)
+	assert.Eq(t, 510, pb.Len())
+	assert.True(t, cap(pb.B) <= 1024)
+	_, err = pb.WriteString(strings.Repeat("x", 491))
+	assert.Equal(t, ErrTooLarge, err)
+
+	// A capped pipe carries far more than the limit in total.
+	pb.Reset()
+	chunk := make([]byte, 300)
+	for i := 0; i < 100; i++ {
+		_, err = pb.Write(chunk)
+		assert.NoErr(t, err)
+		pb.Next(300)
+	}
+	n, err := pb.ReadFrom(strings.NewReader(strings.Repeat("r", 1000)))
+	assert.NoErr(t, err)
+	assert.Eq(t, int64(1000), n)
+}
+
+func TestBytes_ReadFromMaxSize(t *testing.T) {
+	s := strings.Repeat("0123456789", 300)
+	pb := Get(16)
+	defer pb.Release()
+	pb.SetMaxSize(1000)
+	n, err := pb.ReadFrom(strings.NewReader(s))
+	assert.Equal(t, ErrTooLarge, err)
+	assert.Eq(t, int64(1000), n)
+	assert.Eq(t, s[:1000], pb.String())
+
+	pb.Reset()
+	n, err = pb.ReadFrom(strings.NewReader(s[:1000]))
+	assert.NoErr(t, err)
+	assert.Eq(t, int64(1000), n)
+}
+
+func TestBytes_ReadFromLimit(t *testing.T) {
+	s := strings.Repeat("abcdefgh", 200)
+	pb := Get(0)
+	defer pb.Release()
+	n, err := pb.ReadFromLimit(strings.NewReader(s), int64(len(s)))
+	assert.NoErr(t, err)
+	assert.Eq(t, int64(len(s)), n)
+	assert.Eq(t, s, pb.String())
+
+	pb.Reset()
+	n, err = pb.ReadFromLimit(strings.NewReader(s), 999)
+	assert.Equal(t, ErrTooLarge, err)
+	assert.Eq(t, int64(999), n)
+	assert.Eq(t, s[:999], pb.String())
+
+	// The tighter of the two limits wins.
+	pb.Reset()
+	pb.SetMaxSize(10)
+	n, err = pb.ReadFromLimit(strings.NewReader(s), 999)
+	assert.Equal(t, ErrTooLarge, err)
+	assert.Eq(t, int64(10), n)
+}
+
+func TestPut_ClearsMaxSize(t *testing.T) {
+	bp := New(WithRetention(Retention{MaxBuffers: 1}))
+	pb := bp.Get(64)
+	pb.SetMaxSize(10)
+	bp.Put(pb)
+	pb = bp.Get(64)
+	assert.Eq(t, 0, pb.MaxSize())
+	_, err := pb.Write(make([]byte, 64))
+	assert.NoErr(t, err)
+}
@@ -3,9 +3,11 @@ package bpool
 import (
 	"bufio"
 	"errors"
+	"fmt"
 	"github.com/newacorn/goutils/unsafefn"
 	"io"
 	"sync"
+	"sync/atomic"
 	"unicode/utf8"
 )
 
@@ -17,33 +19,88 @@ const (
 )
 
 type BytesPool struct {
-	pools [_NumSizeClasses]sync.Pool
+	// table is replaced as a whole by SetClasses.
+	table        atomic.Pointer[classTable]
+	config       poolConfig
+	poolCounters poolCounters
+	debug        bool
+	// instrumented is set when Get and Put feed the histogram, debug or
+	// leak tracking options.
+	instrumented bool
+	tracker      *leakTracker
+	histogram    *sizeHistogram
+}
+
+// classTable holds the size classes of a BytesPool and the free buffers and
+// counters of each class.
+type classTable struct {
+	classes *sizeClasses
+	pools   []sync.Pool
+	// retained holds the classes that keep their free buffers themselves,
+	// see WithRetention and WithLargeClasses; nil entries use pools.
+	retained []*boundedStore
+	counters []classCounters
+	// fixed is set for the built-in classes when no option needs Get and
+	// Put to do more than pick a pool; they then take a fast path indexing
+	// it with size2class.
+	fixed *[_NumSizeClasses]sync.Pool
 }
 
 type Bytes struct {
 	B []byte
-}
-
+	// off is the read offset into B, see Read.
+	off      int
+	lastRead readOp
+	// maxSize limits Len() when positive, see SetMaxSize.
+	maxSize int
+	// alloc is the Allocator b came from, nil means the default one.
+	alloc Allocator
+	dbg   *debugInfo
+}
+
+// Bytes returns the unread portion of ByteBuffer.B, which is all of it
+// unless b has been read from.
 func (b *Bytes) Bytes() []byte {
-	return b.B
+	return b.B[b.off:]
 }
 
+// Len returns the number of unread bytes.
 func (b *Bytes) Len() int {
-	return len(b.B)
+	return len(b.B) - b.off
 }
 func (b *Bytes) Cap() int {
 	return cap(b.B)
 }
+
+// Release returns b to the Allocator it was obtained from.
 func (b *Bytes) Release() {
-	defaultPool.Put(b)
+	b.Allocator().Put(b)
+}
+
+// Allocator returns the Allocator that b grows through and is released to:
+// the one set by SetAllocator, or the default Allocator.
+func (b *Bytes) Allocator() Allocator {
+	if b.alloc != nil {
+		return b.alloc
+	}
+	return defaultAllocator
+}
+
+// SetAllocator makes b grow through and be released to a. An Allocator that
+// wraps another one calls it in Get, so the buffers it hands out keep coming
+// back to it.
+func (b *Bytes) SetAllocator(a Allocator) {
+	b.alloc = a
 }
 
 // ReadFrom The function appends all the data read from r to b.
 
-// WriteTo implements io.WriterTo.
+// WriteTo implements io.WriterTo. It writes the unread portion of the buffer
+// and leaves b unchanged, so the same content can be written again; see
+// DrainTo for the consuming variant.
 func (b *Bytes) WriteTo(w io.Writer) (n int64, err error) {
-	if nBytes := len(b.B); nBytes > 0 {
-		m, e := w.Write(b.B)
+	if nBytes := b.Len(); nBytes > 0 {
+		m, e := w.Write(b.B[b.off:])
 		if m > nBytes {
 			panic("bpool.Bytes.WriteTo: invalid Write count")
 		}
@@ -60,14 +117,45 @@ func (b *Bytes) WriteTo(w io.Writer) (n int64, err error) {
 	return
 }
 
+// DrainTo is WriteTo as bytes.Buffer implements it: it consumes the written
+// bytes, and resets b once all of them are written.
+func (b *Bytes) DrainTo(w io.Writer) (n int64, err error) {
+	b.lastRead = opInvalid
+	if nBytes := b.Len(); nBytes > 0 {
+		m, e := w.Write(b.B[b.off:])
+		if m > nBytes {
+			panic("bpool.Bytes.DrainTo: invalid Write count")
+		}
+		b.off += m
+		n = int64(m)
+		if e != nil {
+			err = e
+			return
+		}
+		if m != nBytes {
+			err = io.ErrShortWrite
+			return
+		}
+	}
+	b.Reset()
+	return
+}
+
 func (b *Bytes) WriteRune(r rune) (n int, err error) {
 	// Compare as uint32 to correctly handle negative runes.
 	if uint32(r) < utf8.RuneSelf {
-		//goland:noinspection GoUnhandledErrorResult
-		b.WriteByte(byte(r))
+		if err = b.WriteByte(byte(r)); err != nil {
+			return
+		}
 		return 1, nil
 	}
-	b.Grow(utf8.UTFMax)
+	size := utf8.RuneLen(r)
+	if size < 0 {
+		size = len(string(utf8.RuneError))
+	}
+	if err = b.Grow(size); err != nil {
+		return
+	}
 	oldL := len(b.B)
 	b.B = utf8.AppendRune(b.B, r)
 	n = len(b.B) - oldL
@@ -76,11 +164,16 @@ func (b *Bytes) WriteRune(r rune) (n int, err error) {
 
 // Write implements io.Writer - it appends p to ByteBuffer.B
 func (b *Bytes) Write(p []byte) (n int, err error) {
+	b.checkLive("Write")
+	b.lastRead = opInvalid
 	n = len(p)
-	if cap(b.B)-len(b.B) >= len(p) {
+	if cap(b.B)-len(b.B) >= len(p) && (b.maxSize <= 0 || len(b.B)-b.off+len(p) <= b.maxSize) {
 		b.B = append(b.B, p...)
 		return
 	}
+	if b.tooLarge(len(p)) {
+		return 0, ErrTooLarge
+	}
 	b.slowWrite(p)
 	return
 }
@@ -94,61 +187,67 @@ func (b *Bytes) Available() int {
 }
 
 func (b *Bytes) slowWrite(p []byte) {
-	b2 := Get(len(p) + len(b.B) + len(p)>>1)
-	b2.B = b2.B[:len(b.B)+len(p)]
-	copy(b2.B, b.B)
-	copy(b2.B[len(b.B):], p)
-	b.B, b2.B = b2.B, b.B
-	Put(b2)
-	return
+	b.reserve(len(p), b.growSize(b.Len()+len(p)+len(p)>>1))
+	b.B = append(b.B, p...)
 }
 
 func (b *Bytes) slowWriteStr(p string) {
-	b2 := Get(len(p) + len(b.B) + len(p)>>1)
-	b2.B = b2.B[:len(b.B)+len(p)]
-	copy(b2.B, b.B)
-	copy(b2.B[len(b.B):], p)
-	b.B, b2.B = b2.B, b.B
-	Put(b2)
-	return
+	b.reserve(len(p), b.growSize(b.Len()+len(p)+len(p)>>1))
+	b.B = append(b.B, p...)
 }
 
 // WriteByte appends the byte c to the buffer.
 //
 // The purpose of this function is bytes.Buffer compatibility.
 //
-// The function always returns nil.
+// The function returns nil unless the byte would exceed the limit set with
+// SetMaxSize, in which case it returns ErrTooLarge.
 func (b *Bytes) WriteByte(c byte) error {
-	b.Grow(1)
+	if err := b.Grow(1); err != nil {
+		return err
+	}
 	b.B = b.B[:len(b.B)+1]
 	b.B[len(b.B)-1] = c
 	return nil
 }
 
+// UnWriteBytes removes the last n unread bytes.
 func (b *Bytes) UnWriteBytes(n int) {
-	if n > len(b.B) {
-		n = len(b.B)
+	if n > b.Len() {
+		n = b.Len()
 	}
 	b.B = b.B[:len(b.B)-n]
 }
 
+// Swap replaces ByteBuffer.B with new and returns the old one, whether read
+// from or not.
 func (b *Bytes) Swap(new []byte) (old []byte) {
 	b.B, old = new, b.B
+	b.off = 0
+	b.lastRead = opInvalid
 	return
 }
 
-// WriteString appends s to ByteBuffer.B.
-func (b *Bytes) WriteString(s string) {
-	if cap(b.B)-len(b.B) >= len(s) {
+// WriteString appends s to ByteBuffer.B. It returns ErrTooLarge, and
+// appends nothing, if s would exceed the limit set with SetMaxSize.
+func (b *Bytes) WriteString(s string) (n int, err error) {
+	b.checkLive("WriteString")
+	b.lastRead = opInvalid
+	n = len(s)
+	if cap(b.B)-len(b.B) >= len(s) && (b.maxSize <= 0 || len(b.B)-b.off+len(s) <= b.maxSize) {
 		b.B = append(b.B, s...)
 		return
 	}
+	if b.tooLarge(len(s)) {
+		return 0, ErrTooLarge
+	}
 	b.slowWriteStr(s)
+	return
 }
 
 // Set sets ByteBuffer.B to p.
 func (b *Bytes) Set(p []byte) {
-	b.B = b.B[:0]
+	b.Reset()
 	//goland:noinspection GoUnhandledErrorResult
 	b.Write(p)
 }
@@ -158,43 +257,153 @@ func (b *Bytes) SetString(s string) {
 	b.Set(unsafefn.S2B(s))
 }
 
-// String returns string representation of ByteBuffer.B.
+// String returns the unread portion of ByteBuffer.B as a string.
 func (b *Bytes) String() string {
-	return string(b.B)
+	return string(b.B[b.off:])
 }
 
+// UnsafeString is String without the copy; the result is only valid until
+// the next change of b.
 func (b *Bytes) UnsafeString() string {
-	return unsafefn.B2S(b.B)
+	return unsafefn.B2S(b.B[b.off:])
 }
 
 // Reset makes ByteBuffer.B empty.
 func (b *Bytes) Reset() {
 	b.B = b.B[:0]
+	b.off = 0
+	b.lastRead = opInvalid
 }
 
 // MinRead is the minimum slice size passed to a Read call by
-// [Buffer.ReadFrom]. As long as the [Buffer] has at least MinRead bytes beyond
+// [Bytes.ReadFrom]. As long as the [Bytes] has at least MinRead bytes beyond
 // what is required to hold the contents of r, ReadFrom will not grow the
 // underlying buffer.
 const MinRead = 512
 
-func (b *Bytes) Grow(n int) {
-	if cap(b.B)-len(b.B) < n {
-		b2 := Get(len(b.B) + n)
-		b2.B = append(b2.B, b.B...)
-		b2.B, b.B = b.B, b2.B
-		Put(b2)
+// ErrTooLarge is returned by the write methods of a Bytes whose content
+// would exceed the limit set with SetMaxSize, and by ReadFromLimit.
+var ErrTooLarge = errors.New("bpool.Bytes: too large")
+
+// SetMaxSize limits the unread portion of the buffer, Len(), to n bytes;
+// n <= 0 removes the limit. The bytes already read do not count, so a Bytes
+// used as a pipe can carry any amount of data as long as the reader keeps up.
+// Write, WriteString, WriteByte, WriteRune, WriteAt, Grow and ReadFrom return
+// ErrTooLarge instead of growing past the limit, and the Append methods panic
+// with it. The limit is cleared when b is put back to its pool.
+func (b *Bytes) SetMaxSize(n int) {
+	b.maxSize = n
+}
+
+// MaxSize returns the limit set with SetMaxSize, 0 if there is none.
+func (b *Bytes) MaxSize() int {
+	return max(b.maxSize, 0)
+}
+
+// tooLarge reports whether appending n bytes would exceed the limit.
+func (b *Bytes) tooLarge(n int) bool {
+	return b.maxSize > 0 && n > b.maxSize-b.Len()
+}
+
+// growSize clamps the size of a growth request to the limit.
+func (b *Bytes) growSize(size int) int {
+	if b.maxSize > 0 && size > b.maxSize {
+		return b.maxSize
+	}
+	return size
+}
+
+// Grow grows the buffer's capacity, if necessary, to guarantee space for
+// another n bytes, taking the new buffer from b's Allocator. Like
+// bytes.Buffer, it first reuses the space of the bytes already read. It
+// returns ErrTooLarge, and does not grow, if n more bytes would exceed the
+// limit set with SetMaxSize.
+func (b *Bytes) Grow(n int) error {
+	b.checkLive("Grow")
+	b.lastRead = opInvalid
+	if b.tooLarge(n) {
+		return ErrTooLarge
 	}
+	b.grow(n)
+	return nil
+}
+
+// grow is Grow without the limit check, for callers that enforce the limit
+// on what they actually append.
+func (b *Bytes) grow(n int) {
+	b.reserve(n, b.Len()+n)
+}
+
+// reserve makes room for n more bytes at the end of ByteBuffer.B. As
+// bytes.Buffer does, it slides the unread portion to the front if that
+// leaves room for n; otherwise it takes a buffer of at least size bytes from
+// b's Allocator and moves only the unread portion into it. Either way the
+// bytes already read are dropped.
+func (b *Bytes) reserve(n, size int) {
+	if cap(b.B)-len(b.B) >= n {
+		return
+	}
+	m := b.Len()
+	if m+n <= cap(b.B) {
+		copy(b.B, b.B[b.off:])
+		b.B = b.B[:m]
+		b.off = 0
+		return
+	}
+	alloc := b.Allocator()
+	b2 := alloc.Get(size)
+	b2.B = append(b2.B, b.B[b.off:]...)
+	b.B, b2.B = b2.B, b.B
+	b.off = 0
+	alloc.Put(b2)
 }
 
 // ReadFrom reads data from r until EOF and appends it to the buffer, growing
 // the buffer as needed. The return value n is the number of bytes read. Any
-// error except io.EOF encountered during the read is also returned. If the
-// buffer becomes too large, ReadFrom will panic with [ErrTooLarge].
+// error except io.EOF encountered during the read is also returned. If r
+// holds more data than the limit set with SetMaxSize leaves room for,
+// ReadFrom reads up to the limit and returns ErrTooLarge.
 func (b *Bytes) ReadFrom(r io.Reader) (n int64, err error) {
+	return b.readFrom(r, -1)
+}
+
+// ReadFromLimit is ReadFrom reading at most limit bytes from r: if r holds
+// more, it returns ErrTooLarge after the first limit bytes. The limit set with
+// SetMaxSize applies as well. The byte that revealed the excess is consumed
+// from r and dropped.
+func (b *Bytes) ReadFromLimit(r io.Reader, limit int64) (n int64, err error) {
+	if limit < 0 {
+		limit = 0
+	}
+	return b.readFrom(r, limit)
+}
+
+// readFrom implements ReadFrom and ReadFromLimit; limit < 0 means no limit
+// besides maxSize.
+func (b *Bytes) readFrom(r io.Reader, limit int64) (n int64, err error) {
+	b.lastRead = opInvalid
+	if b.maxSize > 0 {
+		room := int64(b.maxSize - b.Len())
+		if limit < 0 || room < limit {
+			limit = room
+		}
+	}
 	for {
-		b.Grow(MinRead)
-		m, e := r.Read(b.B[len(b.B):cap(b.B)])
+		want := int64(MinRead)
+		if limit >= 0 && limit-n < want {
+			want = limit - n
+		}
+		if want <= 0 {
+			return n, probeEOF(r)
+		}
+		if err = b.Grow(int(want)); err != nil {
+			return
+		}
+		end := cap(b.B)
+		if limit >= 0 {
+			end = len(b.B) + int(min(limit-n, int64(end-len(b.B))))
+		}
+		m, e := r.Read(b.B[len(b.B):end])
 		if m < 0 {
 			panic(errNegativeRead)
 		}
@@ -209,6 +418,25 @@ func (b *Bytes) ReadFrom(r io.Reader) (n int64, err error) {
 	}
 }
 
+// probeEOF reads a single byte from r to tell whether r is exhausted. It
+// returns nil at EOF and ErrTooLarge if r had more data.
+func probeEOF(r io.Reader) error {
+	var probe [1]byte
+	for i := 0; i < 100; i++ {
+		m, e := r.Read(probe[:])
+		if m > 0 {
+			return ErrTooLarge
+		}
+		if e == io.EOF {
+			return nil
+		}
+		if e != nil {
+			return e
+		}
+	}
+	return io.ErrNoProgress
+}
+
 //var defaultBufioReaderPool = NewBufioReaderPool(Block4k)
 //var defaultBufioWriterPool = NewBufioWriterPool(Block4k)
 
@@ -365,53 +593,319 @@ func (bwP BufioWriterPool) Put(bw *bufio.Writer) {
 }
 
 func (b *Bytes) RecycleToPool00() {
-	defaultPool.Put(b)
+	b.Allocator().Put(b)
 }
 
+// Allocator hands out Bytes and takes them back. BytesPool is the stock
+// implementation; SetDefault installs another one behind Get, Put and the
+// growth of Bytes that were not obtained from a particular Allocator.
+type Allocator interface {
+	// Get returns an empty Bytes with a capacity of at least size.
+	Get(size int) *Bytes
+	// Put takes back a Bytes that its caller no longer uses.
+	Put(bytes *Bytes)
+}
+
+var _ Allocator = (*BytesPool)(nil)
+
 var defaultPool = New()
 
+var defaultAllocator Allocator = defaultPool
+
+// Default returns the Allocator used by Get and Put.
+func Default() Allocator {
+	return defaultAllocator
+}
+
+// SetDefault replaces the Allocator used by Get and Put and returns the
+// previous one. A nil a restores the built-in pool. SetDefault is not safe
+// for concurrent use with Get and Put; call it during initialization, e.g.
+// from an init function or TestMain.
+func SetDefault(a Allocator) (prev Allocator) {
+	prev = defaultAllocator
+	if a == nil {
+		a = defaultPool
+	}
+	defaultAllocator = a
+	return
+}
+
 func Get(size int) *Bytes {
-	return defaultPool.Get(size)
+	return defaultAllocator.Get(size)
 }
 func Put(bytes *Bytes) {
-	defaultPool.Put(bytes)
+	defaultAllocator.Put(bytes)
+}
+
+// Option configures a BytesPool created by New or NewWithClasses.
+type Option func(c *poolConfig)
+
+// poolConfig collects the options of a BytesPool; the pool is built from it
+// once all options have been applied.
+type poolConfig struct {
+	stats     bool
+	debug     bool
+	tracking  bool
+	histogram bool
+	retention *Retention
+	large     *largeClasses
 }
 
-func New() (bp *BytesPool) {
+// WithStats enables the per-class counters reported by BytesPool.Stats.
+// Every Get and Put then pays for an atomic add, so it is off by default.
+func WithStats() Option {
+	return func(c *poolConfig) {
+		c.stats = true
+	}
+}
+
+// New returns a BytesPool using the built-in size classes. It panics if the
+// options extend the class table in an invalid way.
+func New(opts ...Option) (bp *BytesPool) {
+	bp, err := newBytesPool(defaultClasses, opts)
+	if err != nil {
+		panic(err)
+	}
+	return
+}
+
+// NewWithClasses returns a BytesPool whose size classes are the given sizes
+// instead of the built-in table. classes must be strictly increasing and
+// positive; Get serves sizes up to the last class from the pool and
+// allocates larger ones directly, and Put drops buffers whose capacity is
+// smaller than the first class. Above 32 KB each power of two is looked up in
+// 16 ranges of equal width, and at most 8 classes may fall in one of them.
+func NewWithClasses(classes []int, opts ...Option) (bp *BytesPool, err error) {
+	sc, err := newSizeClasses(classes)
+	if err != nil {
+		return
+	}
+	return newBytesPool(sc, opts)
+}
+
+func newBytesPool(sc *sizeClasses, opts []Option) (bp *BytesPool, err error) {
 	bp = &BytesPool{}
-	for i := 0; i < _NumSizeClasses; i++ {
-		bp.pools[i].New = func() interface{} {
-			return &Bytes{B: unsafefn.Bytes(0, int(class_to_size[i]))}
+	for _, opt := range opts {
+		opt(&bp.config)
+	}
+	bp.instrumented = bp.config.debug || bp.config.tracking || bp.config.histogram
+	t, err := bp.newClassTable(sc)
+	if err != nil {
+		return nil, err
+	}
+	bp.table.Store(t)
+	bp.debug = bp.config.debug
+	if bp.config.tracking {
+		bp.tracker = &leakTracker{live: make(map[*Bytes]*OutstandingBuffer)}
+	}
+	if bp.config.histogram {
+		bp.histogram = &sizeHistogram{}
+	}
+	return
+}
+
+// newClassTable builds the class table for sc according to the options of bp.
+func (bp *BytesPool) newClassTable(sc *sizeClasses) (t *classTable, err error) {
+	c := &bp.config
+	numClasses := len(sc.classToSize)
+	if c.large != nil {
+		if c.large.classes[0] <= sc.maxSize {
+			return nil, fmt.Errorf("%w: large class %d not above the largest class %d",
+				ErrInvalidClasses, c.large.classes[0], sc.maxSize)
+		}
+		sc, err = newSizeClasses(append(sc.classes(), c.large.classes...))
+		if err != nil {
+			return
+		}
+	}
+	t = &classTable{classes: sc}
+	if c.retention != nil || c.large != nil {
+		var largeBudget *retentionBudget
+		if c.large != nil {
+			largeBudget = newRetentionBudget(c.large.limit)
+		}
+		t.retained = make([]*boundedStore, len(sc.classToSize))
+		for i := range t.retained {
+			if i >= numClasses {
+				t.retained[i] = &boundedStore{budget: largeBudget}
+			} else if c.retention != nil {
+				t.retained[i] = &boundedStore{budget: newRetentionBudget(*c.retention)}
+			}
+		}
+	}
+	if c.retention == nil {
+		if sc == defaultClasses && !c.stats && !bp.instrumented {
+			t.fixed = new([_NumSizeClasses]sync.Pool)
+			t.pools = t.fixed[:]
+		} else {
+			t.pools = make([]sync.Pool, len(sc.classToSize))
 		}
 	}
+	if c.stats {
+		t.counters = make([]classCounters, len(sc.classToSize))
+	}
+	return
+}
+
+// Classes returns the class sizes used by bp, in increasing order.
+func (bp *BytesPool) Classes() []int {
+	return bp.table.Load().classes.classes()
+}
+
+// SetClasses replaces the size classes of a running pool; classes follows
+// the rules of NewWithClasses, and large classes configured with
+// WithLargeClasses are kept on top of them. The free buffers cached for the
+// old classes are dropped and the per-class counters start from zero.
+// Buffers obtained before the switch can still be put back: Put files them
+// under the new class that fits their capacity.
+func (bp *BytesPool) SetClasses(classes []int) (err error) {
+	sc, err := newSizeClasses(classes)
+	if err != nil {
+		return
+	}
+	t, err := bp.newClassTable(sc)
+	if err != nil {
+		return
+	}
+	bp.table.Store(t)
 	return
 }
+
 func (bp *BytesPool) Get(size int) *Bytes {
+	t := bp.table.Load()
+	if t.fixed == nil {
+		return bp.get(t, size)
+	}
 	if size == 0 {
-		return &Bytes{}
+		return &Bytes{alloc: bp}
 	}
 	if size <= _MaxBigSize {
-		return bp.pools[size2class(size)].Get().(*Bytes)
+		class := size2class(size)
+		if v := t.fixed[class].Get(); v != nil {
+			bytes := v.(*Bytes)
+			// A wrapper Allocator may have claimed it with SetAllocator.
+			bytes.alloc = bp
+			return bytes
+		}
+		return &Bytes{B: unsafefn.Bytes(0, int(class_to_size[class])), alloc: bp}
+	}
+	return &Bytes{B: unsafefn.Bytes(0, size), alloc: bp}
+}
+
+// get is Get for the pools that count, record or retain buffers.
+func (bp *BytesPool) get(t *classTable, size int) (bytes *Bytes) {
+	var class uint8
+	if size == 0 {
+		bytes = &Bytes{alloc: bp}
+	} else if size <= t.classes.maxSize {
+		class = t.classes.sizeToClass(size)
+		if t.retained != nil && t.retained[class] != nil {
+			bytes = t.retained[class].get()
+		} else if v := t.pools[class].Get(); v != nil {
+			bytes = v.(*Bytes)
+		}
+		if bytes != nil {
+			bytes.alloc = bp
+			if t.counters != nil {
+				t.counters[class].hits.Add(1)
+			}
+		} else {
+			if t.counters != nil {
+				t.counters[class].misses.Add(1)
+			}
+			bytes = &Bytes{B: unsafefn.Bytes(0, int(t.classes.classToSize[class])), alloc: bp}
+		}
+	} else {
+		if t.counters != nil {
+			bp.poolCounters.oversized.Add(1)
+		}
+		bytes = &Bytes{B: unsafefn.Bytes(0, size), alloc: bp}
+	}
+	if bp.instrumented {
+		bp.instrumentGet(bytes, size, int(t.classes.classToSize[class]))
+	}
+	return
+}
+
+// instrumentGet records a Get of size served with bytes for the histogram,
+// debug and leak tracking options.
+func (bp *BytesPool) instrumentGet(bytes *Bytes, size, classSize int) {
+	if bp.histogram != nil {
+		bp.histogram.record(size)
+	}
+	if bp.debug {
+		bp.debugGet(bytes)
+	}
+	if bp.tracker != nil {
+		bp.tracker.add(bytes, size, classSize)
 	}
-	return &Bytes{B: unsafefn.Bytes(0, size)}
 }
+
 func (bp *BytesPool) Put(bytes *Bytes) {
-	if bytes == nil || cap(bytes.B) > _MaxBigSize || cap(bytes.B) < _MinByteSize {
+	if bytes == nil {
+		return
+	}
+	t := bp.table.Load()
+	if t.fixed == nil {
+		bp.put(t, bytes)
+		return
+	}
+	c := cap(bytes.B)
+	if c > _MaxBigSize || c < _MinByteSize {
 		return
 	}
-	class := size2class(cap(bytes.B))
-	floorSize := class_to_size[class]
-	if cap(bytes.B) < int(floorSize) {
-		if cap(bytes.B) <= _MaxSmallSize {
-			// class cant less  zero
-			// because  cap(bytes.B) >= _MinByteSize
-			class = class - 1
+	class := size2class(c)
+	if c < int(class_to_size[class]) {
+		// class cant less one
+		// because c >= _MinByteSize
+		class--
+	}
+	bytes.Reset()
+	bytes.maxSize = 0
+	t.fixed[class].Put(bytes)
+}
+
+// put is Put for the pools that count, record or retain buffers.
+func (bp *BytesPool) put(t *classTable, bytes *Bytes) {
+	if bp.instrumented {
+		bytes = bp.instrumentPut(bytes)
+	}
+	class, ok := t.classes.putClass(cap(bytes.B))
+	if ok {
+		bytes.Reset()
+		bytes.maxSize = 0
+		if t.retained != nil && t.retained[class] != nil {
+			ok = t.retained[class].put(bytes)
 		} else {
+			t.pools[class].Put(bytes)
+		}
+	}
+	if !ok {
+		if t.counters == nil {
 			return
 		}
+		if class != 0 {
+			t.counters[class].rejectedPuts.Add(1)
+		} else {
+			bp.poolCounters.rejectedPuts.Add(1)
+		}
+		return
+	}
+	if t.counters != nil {
+		t.counters[class].puts.Add(1)
+	}
+}
+
+// instrumentPut is the counterpart of instrumentGet; it returns the Bytes to
+// store in place of bytes.
+func (bp *BytesPool) instrumentPut(bytes *Bytes) *Bytes {
+	if bp.tracker != nil {
+		bp.tracker.remove(bytes)
+	}
+	if bp.debug {
+		bytes = bp.debugPut(bytes)
 	}
-	bytes.B = bytes.B[:0]
-	bp.pools[class].Put(bytes)
+	return bytes
 }
 
 func Copy(dst io.Writer, src io.Reader) (written int64, err error) {
@@ -0,0 +1,115 @@
+package bpool
+
+import (
+	"bytes"
+	"unicode"
+	"unicode/utf8"
+)
+
+// The query methods are the functions of package bytes of the same name
+// applied to the unread portion of the buffer, Bytes().
+
+// Index returns the index of the first instance of sep, or -1.
+func (b *Bytes) Index(sep []byte) int {
+	return bytes.Index(b.B[b.off:], sep)
+}
+
+// IndexByte returns the index of the first instance of c, or -1.
+func (b *Bytes) IndexByte(c byte) int {
+	return bytes.IndexByte(b.B[b.off:], c)
+}
+
+// LastIndex returns the index of the last instance of sep, or -1.
+func (b *Bytes) LastIndex(sep []byte) int {
+	return bytes.LastIndex(b.B[b.off:], sep)
+}
+
+// Contains reports whether sub is within the unread portion.
+func (b *Bytes) Contains(sub []byte) bool {
+	return bytes.Contains(b.B[b.off:], sub)
+}
+
+// HasPrefix reports whether the unread portion begins with prefix.
+func (b *Bytes) HasPrefix(prefix []byte) bool {
+	return bytes.HasPrefix(b.B[b.off:], prefix)
+}
+
+// HasSuffix reports whether the unread portion ends with suffix.
+func (b *Bytes) HasSuffix(suffix []byte) bool {
+	return bytes.HasSuffix(b.B[b.off:], suffix)
+}
+
+// Equal reports whether the unread portion is the same as p.
+func (b *Bytes) Equal(p []byte) bool {
+	return bytes.Equal(b.B[b.off:], p)
+}
+
+// EqualFold reports whether the unread portion and p, interpreted as UTF-8,
+// are equal under simple Unicode case-folding.
+func (b *Bytes) EqualFold(p []byte) bool {
+	return bytes.EqualFold(b.B[b.off:], p)
+}
+
+// Count counts the non-overlapping instances of sep; if sep is empty, it
+// returns 1 + the number of UTF-8-encoded code points.
+func (b *Bytes) Count(sep []byte) int {
+	return bytes.Count(b.B[b.off:], sep)
+}
+
+// SplitFunc calls yield with each of the sub-slices separated by sep that
+// bytes.Split would return, in order, until yield returns false. If sep is
+// empty, it splits after each UTF-8 sequence. Unlike bytes.Split it does not
+// allocate; the sub-slices are only valid until the next change of b.
+func (b *Bytes) SplitFunc(sep []byte, yield func(field []byte) bool) {
+	s := b.B[b.off:]
+	if len(sep) == 0 {
+		for len(s) > 0 {
+			_, size := utf8.DecodeRune(s)
+			if !yield(s[:size:size]) {
+				return
+			}
+			s = s[size:]
+		}
+		return
+	}
+	for {
+		i := bytes.Index(s, sep)
+		if i < 0 {
+			break
+		}
+		if !yield(s[:i:i]) {
+			return
+		}
+		s = s[i+len(sep):]
+	}
+	yield(s[:len(s):len(s)])
+}
+
+// EachField calls yield with each of the runs of bytes that bytes.Fields
+// would return, the fields separated by Unicode white space, until yield
+// returns false. Like SplitFunc it does not allocate.
+func (b *Bytes) EachField(yield func(field []byte) bool) {
+	s := b.B[b.off:]
+	start := -1
+	for i := 0; i < len(s); {
+		size := 1
+		r := rune(s[i])
+		if r >= utf8.RuneSelf {
+			r, size = utf8.DecodeRune(s[i:])
+		}
+		if unicode.IsSpace(r) {
+			if start >= 0 {
+				if !yield(s[start:i:i]) {
+					return
+				}
+				start = -1
+			}
+		} else if start < 0 {
+			start = i
+		}
+		i += size
+	}
+	if start >= 0 {
+		yield(s[start:len(s):len(s)])
+	}
+}
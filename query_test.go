@@ -0,0 +1,100 @@
+package bpool
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestBytes_Query(t *testing.T) {
+	pb := Get(64)
+	defer pb.Release()
+	pb.WriteString("skip|Content-Length: 42; Content-Type: text")
+	pb.Next(5)
+	assert.Eq(t, 0, pb.Index([]byte("Content")))
+	assert.Eq(t, 20, pb.LastIndex([]byte("Content")))
+	assert.Eq(t, 14, pb.IndexByte(':'))
+	assert.Eq(t, -1, pb.IndexByte('|'))
+	assert.True(t, pb.Contains([]byte("42;")))
+	assert.False(t, pb.Contains([]byte("skip")))
+	assert.True(t, pb.HasPrefix([]byte("Content-Length")))
+	assert.True(t, pb.HasSuffix([]byte("text")))
+	assert.Eq(t, 2, pb.Count([]byte("Content")))
+	assert.Eq(t, pb.Len()+1, pb.Count(nil))
+
+	pb.Reset()
+	pb.WriteString("Straße")
+	assert.True(t, pb.Equal([]byte("Straße")))
+	assert.False(t, pb.Equal([]byte("straße")))
+	assert.True(t, pb.EqualFold([]byte("STRAßE")))
+}
+
+func collect(f func(yield func([]byte) bool)) (fields []string) {
+	f(func(p []byte) bool {
+		fields = append(fields, string(p))
+		return true
+	})
+	return
+}
+
+func TestBytes_SplitFunc(t *testing.T) {
+	for _, c := range []struct{ s, sep string }{
+		{"a,b,,c", ","},
+		{",a,", ","},
+		{"", ","},
+		{"no separator", ";"},
+		{"a--b----c", "--"},
+		{"héllo", ""},
+		{"", ""},
+	} {
+		pb := Get(16)
+		pb.WriteString(c.s)
+		got := collect(func(yield func([]byte) bool) { pb.SplitFunc([]byte(c.sep), yield) })
+		var want []string
+		for _, p := range bytes.Split([]byte(c.s), []byte(c.sep)) {
+			want = append(want, string(p))
+		}
+		assert.Eq(t, want, got, c)
+		pb.Release()
+	}
+}
+
+func TestBytes_EachField(t *testing.T) {
+	for _, s := range []string{"", "  ", " a b\t\tc\n", "one", "x y z "} {
+		pb := Get(16)
+		pb.WriteString(s)
+		got := collect(pb.EachField)
+		var want []string
+		for _, p := range bytes.Fields([]byte(s)) {
+			want = append(want, string(p))
+		}
+		assert.Eq(t, want, got, s)
+		pb.Release()
+	}
+}
+
+func TestBytes_SplitFuncStop(t *testing.T) {
+	pb := Get(32)
+	defer pb.Release()
+	pb.WriteString("k1=v1&k2=v2&k3=v3")
+	var seen []string
+	pb.SplitFunc([]byte("&"), func(p []byte) bool {
+		seen = append(seen, string(p))
+		return !bytes.HasPrefix(p, []byte("k2="))
+	})
+	assert.Eq(t, []string{"k1=v1", "k2=v2"}, seen)
+
+	n := 0
+	allocs := testing.AllocsPerRun(100, func() {
+		pb.SplitFunc([]byte("&"), func(p []byte) bool {
+			n += len(p)
+			return true
+		})
+		pb.EachField(func(p []byte) bool {
+			n += len(p)
+			return true
+		})
+	})
+	assert.Eq(t, float64(0), allocs)
+}
@@ -0,0 +1,189 @@
+package bpool
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"unicode/utf8"
+)
+
+// The readOp constants describe the last action performed on a Bytes, so
+// that UnreadRune and UnreadByte can check for invalid usage. opReadRuneX
+// constants are chosen such that converted to int they correspond to the
+// rune size that was read.
+type readOp int8
+
+// Don't use iota for these, as the values need to correspond with the
+// names and comments, which is easier to see when being explicit.
+const (
+	opRead      readOp = -1 // Any other read operation.
+	opInvalid   readOp = 0  // Non-read operation.
+	opReadRune1 readOp = 1  // Read rune of size 1.
+	opReadRune2 readOp = 2  // Read rune of size 2.
+	opReadRune3 readOp = 3  // Read rune of size 3.
+	opReadRune4 readOp = 4  // Read rune of size 4.
+)
+
+var errUnreadByte = errors.New("bpool.Bytes: UnreadByte: previous operation was not a successful read")
+var errUnreadRune = errors.New("bpool.Bytes: UnreadRune: previous operation was not a successful ReadRune")
+
+// empty reports whether the unread portion of the buffer is empty.
+func (b *Bytes) empty() bool { return len(b.B) <= b.off }
+
+// Truncate discards all but the first n unread bytes from the buffer but
+// continues to use the same allocated storage. It panics if n is negative or
+// greater than the length of the buffer.
+func (b *Bytes) Truncate(n int) {
+	if n == 0 {
+		b.Reset()
+		return
+	}
+	b.lastRead = opInvalid
+	if n < 0 || n > b.Len() {
+		panic("bpool.Bytes: truncation out of range")
+	}
+	b.B = b.B[:b.off+n]
+}
+
+// Read reads the next len(p) bytes from the buffer or until the buffer is
+// drained. The return value n is the number of bytes read. If the buffer has
+// no data to return, err is io.EOF (unless len(p) is zero); otherwise it is
+// nil.
+func (b *Bytes) Read(p []byte) (n int, err error) {
+	b.lastRead = opInvalid
+	if b.empty() {
+		// Buffer is empty, reset to recover space.
+		b.Reset()
+		if len(p) == 0 {
+			return 0, nil
+		}
+		return 0, io.EOF
+	}
+	n = copy(p, b.B[b.off:])
+	b.off += n
+	if n > 0 {
+		b.lastRead = opRead
+	}
+	return n, nil
+}
+
+// Next returns a slice containing the next n bytes from the buffer,
+// advancing the buffer as if the bytes had been returned by Read. If there
+// are fewer than n bytes in the buffer, Next returns the entire buffer. The
+// slice is only valid until the next call to a read or write method.
+func (b *Bytes) Next(n int) []byte {
+	b.lastRead = opInvalid
+	m := b.Len()
+	if n > m {
+		n = m
+	}
+	data := b.B[b.off : b.off+n]
+	b.off += n
+	if n > 0 {
+		b.lastRead = opRead
+	}
+	return data
+}
+
+// ReadByte reads and returns the next byte from the buffer. If no byte is
+// available, it returns error io.EOF.
+func (b *Bytes) ReadByte() (byte, error) {
+	if b.empty() {
+		// Buffer is empty, reset to recover space.
+		b.Reset()
+		return 0, io.EOF
+	}
+	c := b.B[b.off]
+	b.off++
+	b.lastRead = opRead
+	return c, nil
+}
+
+// ReadRune reads and returns the next UTF-8-encoded Unicode code point from
+// the buffer. If no bytes are available, the error returned is io.EOF. If
+// the bytes are an erroneous UTF-8 encoding, it consumes one byte and
+// returns U+FFFD, 1.
+func (b *Bytes) ReadRune() (r rune, size int, err error) {
+	if b.empty() {
+		// Buffer is empty, reset to recover space.
+		b.Reset()
+		return 0, 0, io.EOF
+	}
+	c := b.B[b.off]
+	if c < utf8.RuneSelf {
+		b.off++
+		b.lastRead = opReadRune1
+		return rune(c), 1, nil
+	}
+	r, n := utf8.DecodeRune(b.B[b.off:])
+	b.off += n
+	b.lastRead = readOp(n)
+	return r, n, nil
+}
+
+// UnreadRune unreads the last rune returned by ReadRune. If the most recent
+// read or write operation on the buffer was not a successful ReadRune,
+// UnreadRune returns an error.
+func (b *Bytes) UnreadRune() error {
+	if b.lastRead <= opInvalid {
+		return errUnreadRune
+	}
+	if b.off >= int(b.lastRead) {
+		b.off -= int(b.lastRead)
+	}
+	b.lastRead = opInvalid
+	return nil
+}
+
+// UnreadByte unreads the last byte returned by the most recent successful
+// read operation that read at least one byte. If a write has happened since
+// the last read, if the last read returned an error, or if the read read
+// zero bytes, UnreadByte returns an error.
+func (b *Bytes) UnreadByte() error {
+	if b.lastRead == opInvalid {
+		return errUnreadByte
+	}
+	b.lastRead = opInvalid
+	if b.off > 0 {
+		b.off--
+	}
+	return nil
+}
+
+// ReadBytes reads until the first occurrence of delim in the input,
+// returning a slice containing the data up to and including the delimiter.
+// If ReadBytes encounters an error before finding a delimiter, it returns
+// the data read before the error and the error itself (often io.EOF).
+// ReadBytes returns err != nil if and only if the returned data does not end
+// in delim. The returned slice is a copy.
+func (b *Bytes) ReadBytes(delim byte) (line []byte, err error) {
+	slice, err := b.readSlice(delim)
+	line = append(line, slice...)
+	return line, err
+}
+
+// readSlice is like ReadBytes but returns a reference to internal buffer
+// data.
+func (b *Bytes) readSlice(delim byte) (line []byte, err error) {
+	i := bytes.IndexByte(b.B[b.off:], delim)
+	end := b.off + i + 1
+	if i < 0 {
+		end = len(b.B)
+		err = io.EOF
+	}
+	line = b.B[b.off:end]
+	b.off = end
+	b.lastRead = opRead
+	return line, err
+}
+
+// ReadString reads until the first occurrence of delim in the input,
+// returning a string containing the data up to and including the delimiter.
+// If ReadString encounters an error before finding a delimiter, it returns
+// the data read before the error and the error itself (often io.EOF).
+// ReadString returns err != nil if and only if the returned data does not
+// end in delim.
+func (b *Bytes) ReadString(delim byte) (line string, err error) {
+	slice, err := b.readSlice(delim)
+	return string(slice), err
+}
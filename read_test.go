@@ -0,0 +1,151 @@
+package bpool
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+// TestBytesMatchesBuffer runs the same random sequence of operations on a
+// Bytes and a bytes.Buffer and compares every result.
+func TestBytesMatchesBuffer(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	text := []byte("héllo, 世界\nfoo bar\nbaz")
+	pb := Get(16)
+	var bb bytes.Buffer
+	for i := 0; i < 20000; i++ {
+		switch op := r.Intn(11); op {
+		case 0:
+			p := text[:r.Intn(len(text))]
+			n1, err1 := pb.Write(p)
+			n2, err2 := bb.Write(p)
+			assert.Eq(t, n2, n1)
+			assert.Eq(t, err2, err1)
+		case 1:
+			n := r.Intn(8)
+			p1, p2 := make([]byte, n), make([]byte, n)
+			n1, err1 := pb.Read(p1)
+			n2, err2 := bb.Read(p2)
+			assert.Eq(t, n2, n1)
+			assert.Eq(t, err2, err1)
+			assert.Eq(t, p2, p1)
+		case 2:
+			c1, err1 := pb.ReadByte()
+			c2, err2 := bb.ReadByte()
+			assert.Eq(t, c2, c1)
+			assert.Eq(t, err2, err1)
+		case 3:
+			r1, s1, err1 := pb.ReadRune()
+			r2, s2, err2 := bb.ReadRune()
+			assert.Eq(t, r2, r1)
+			assert.Eq(t, s2, s1)
+			assert.Eq(t, err2, err1)
+		case 4:
+			assert.Eq(t, bb.UnreadByte() == nil, pb.UnreadByte() == nil)
+		case 5:
+			assert.Eq(t, bb.UnreadRune() == nil, pb.UnreadRune() == nil)
+		case 6:
+			n := r.Intn(8)
+			assert.Eq(t, string(bb.Next(n)), string(pb.Next(n)))
+		case 7:
+			l1, err1 := pb.ReadBytes('\n')
+			l2, err2 := bb.ReadBytes('\n')
+			assert.Eq(t, string(l2), string(l1))
+			assert.Eq(t, err2, err1)
+		case 8:
+			l1, err1 := pb.ReadString(' ')
+			l2, err2 := bb.ReadString(' ')
+			assert.Eq(t, l2, l1)
+			assert.Eq(t, err2, err1)
+		case 9:
+			n := r.Intn(bb.Len() + 1)
+			pb.Truncate(n)
+			bb.Truncate(n)
+		case 10:
+			r := r.Intn(0x2000)
+			_, _ = pb.WriteRune(rune(r))
+			_, _ = bb.WriteRune(rune(r))
+		}
+		assert.Eq(t, bb.Len(), pb.Len())
+		assert.Eq(t, bb.String(), pb.String())
+	}
+	pb.Release()
+}
+
+func TestBytes_WriteToKeepsContent(t *testing.T) {
+	pb := Get(10)
+	defer pb.Release()
+	pb.WriteString("hello world")
+	pb.Next(6)
+	for i := 0; i < 2; i++ {
+		sb := &strings.Builder{}
+		n, err := pb.WriteTo(sb)
+		assert.NoErr(t, err)
+		assert.Eq(t, int64(5), n)
+		assert.Eq(t, "world", sb.String())
+	}
+	assert.Eq(t, "world", pb.String())
+}
+
+func TestBytes_DrainTo(t *testing.T) {
+	pb := Get(10)
+	pb.WriteString("hello world")
+	pb.Next(6)
+	sb := &strings.Builder{}
+	n, err := pb.DrainTo(sb)
+	assert.NoErr(t, err)
+	assert.Eq(t, int64(5), n)
+	assert.Eq(t, "world", sb.String())
+	assert.Eq(t, 0, pb.Len())
+	_, err = pb.Read(make([]byte, 1))
+	assert.Eq(t, io.EOF, err)
+	pb.Release()
+}
+
+func TestBytes_ReleaseResetsOffset(t *testing.T) {
+	bp := New()
+	pb := bp.Get(10)
+	pb.WriteString("hello")
+	pb.Next(3)
+	pb.Release()
+	for i := 0; i < 10; i++ {
+		pb = bp.Get(10)
+		pb.WriteString("x")
+		assert.Eq(t, "x", pb.String())
+	}
+}
+
+func TestBytes_PipeDropsReadBytes(t *testing.T) {
+	pb := Get(0)
+	defer pb.Release()
+	chunk := make([]byte, 200)
+	var w, r byte
+	for i := 0; i < 10000; i++ {
+		for j := range chunk {
+			chunk[j] = w
+			w++
+		}
+		pb.Write(chunk)
+		for _, c := range pb.Next(100) {
+			assert.Eq(t, r, c)
+			r++
+		}
+	}
+	assert.Eq(t, 1000000, pb.Len())
+	// Growing drops the bytes already read instead of copying them along.
+	assert.True(t, len(pb.B) < pb.Len()+pb.Len()/8)
+	assert.Eq(t, r, pb.Bytes()[0])
+
+	// Sliding to the front when the unread bytes and the write fit.
+	pb.Reset()
+	c := cap(pb.B)
+	pb.Write(make([]byte, c))
+	pb.Next(c - 10)
+	pb.Write(make([]byte, 100))
+	assert.Eq(t, c, cap(pb.B))
+	assert.Eq(t, 110, len(pb.B))
+}
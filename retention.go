@@ -0,0 +1,124 @@
+package bpool
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// Retention limits the free buffers kept by the classes configured with
+// WithRetention or WithLargeClasses. A zero field does not limit; at least
+// one of them must be set.
+type Retention struct {
+	// MaxBuffers is the number of free buffers kept.
+	MaxBuffers int
+	// MaxBytes is the total capacity of the free buffers kept.
+	MaxBytes int
+}
+
+// WithRetention makes the pool keep free buffers itself, up to the given
+// per-class limits, instead of in sync.Pools. Unlike a sync.Pool, the cached
+// buffers survive garbage collections, so the pool does not start empty after
+// every GC cycle; the price is that up to the limits worth of memory stays
+// allocated while idle, and that Get and Put take a per-class lock.
+//
+// WithRetention panics if neither limit is set.
+func WithRetention(r Retention) Option {
+	r.check("WithRetention")
+	return func(c *poolConfig) {
+		c.retention = &r
+	}
+}
+
+type largeClasses struct {
+	classes []int
+	limit   Retention
+}
+
+// WithLargeClasses adds size classes above the largest class of the pool,
+// e.g. above the 8 MB of the built-in table, so that Get serves those sizes
+// from the pool and Put recycles them instead of dropping them. The free
+// buffers of all large classes together are kept within r, whatever the
+// storage of the other classes; they survive garbage collections like the
+// classes of WithRetention.
+//
+// WithLargeClasses panics if neither limit of r is set. New panics and
+// NewWithClasses fails if classes is not strictly increasing or does not
+// start above the largest class of the pool.
+func WithLargeClasses(classes []int, r Retention) Option {
+	r.check("WithLargeClasses")
+	if len(classes) == 0 {
+		panic("bpool: WithLargeClasses needs at least one class")
+	}
+	large := &largeClasses{classes: append([]int(nil), classes...), limit: r}
+	return func(c *poolConfig) {
+		c.large = large
+	}
+}
+
+func (r Retention) check(option string) {
+	if r.MaxBuffers <= 0 && r.MaxBytes <= 0 {
+		panic(fmt.Sprintf("bpool: %s needs MaxBuffers or MaxBytes", option))
+	}
+}
+
+// retentionBudget accounts the free buffers kept by one or more classes
+// against a Retention.
+type retentionBudget struct {
+	buffers atomic.Int64
+	bytes   atomic.Int64
+	limit   Retention
+}
+
+func newRetentionBudget(r Retention) *retentionBudget {
+	return &retentionBudget{limit: r}
+}
+
+// reserve accounts a buffer of capacity n, or reports false if that would
+// exceed the limit.
+func (rb *retentionBudget) reserve(n int) bool {
+	buffers, bytes := rb.buffers.Add(1), rb.bytes.Add(int64(n))
+	if (rb.limit.MaxBuffers > 0 && buffers > int64(rb.limit.MaxBuffers)) ||
+		(rb.limit.MaxBytes > 0 && bytes > int64(rb.limit.MaxBytes)) {
+		rb.release(n)
+		return false
+	}
+	return true
+}
+
+func (rb *retentionBudget) release(n int) {
+	rb.buffers.Add(-1)
+	rb.bytes.Add(-int64(n))
+}
+
+// boundedStore is a size class that keeps its free buffers in a stack
+// accounted against a retentionBudget.
+type boundedStore struct {
+	mu     sync.Mutex
+	free   []*Bytes
+	budget *retentionBudget
+}
+
+func (s *boundedStore) get() (b *Bytes) {
+	s.mu.Lock()
+	if n := len(s.free); n > 0 {
+		b = s.free[n-1]
+		s.free[n-1] = nil
+		s.free = s.free[:n-1]
+	}
+	s.mu.Unlock()
+	if b != nil {
+		s.budget.release(cap(b.B))
+	}
+	return
+}
+
+func (s *boundedStore) put(b *Bytes) bool {
+	if !s.budget.reserve(cap(b.B)) {
+		return false
+	}
+	s.mu.Lock()
+	s.free = append(s.free, b)
+	s.mu.Unlock()
+	return true
+}
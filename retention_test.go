@@ -0,0 +1,92 @@
+package bpool
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestRetentionSurvivesGC(t *testing.T) {
+	bp := New(WithRetention(Retention{MaxBuffers: 4}))
+	var got []*Bytes
+	for i := 0; i < 6; i++ {
+		got = append(got, bp.Get(1000))
+	}
+	for _, b := range got {
+		b.Release()
+	}
+	runtime.GC()
+	runtime.GC()
+	for i := 0; i < 4; i++ {
+		b := bp.Get(1000)
+		found := false
+		for _, g := range got {
+			found = found || g == b
+		}
+		if !found {
+			t.Fatalf("Get %d did not return a retained buffer", i)
+		}
+	}
+	for _, g := range got {
+		if g == bp.Get(1000) {
+			t.Fatal("more buffers retained than MaxBuffers")
+		}
+	}
+}
+
+func TestRetentionMaxBytes(t *testing.T) {
+	bp := New(WithRetention(Retention{MaxBytes: 2500}), WithStats())
+	for i := 0; i < 3; i++ {
+		bp.Put(&Bytes{B: make([]byte, 0, 1024)})
+	}
+	s := bp.Stats().Classes[size2class(1024)-1]
+	assert.Eq(t, uint64(2), s.Puts)
+	assert.Eq(t, uint64(1), s.RejectedPuts)
+}
+
+func TestRetentionNoLimit(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("no panic")
+		}
+	}()
+	WithRetention(Retention{})
+}
+
+func TestLargeClasses(t *testing.T) {
+	bp := New(WithLargeClasses([]int{16 << 20, 32 << 20}, Retention{MaxBuffers: 1}), WithStats())
+	assert.Eq(t, 32<<20, bp.Classes()[len(bp.Classes())-1])
+	a := bp.Get(10 << 20)
+	assert.Eq(t, 16<<20, cap(a.B))
+	b := bp.Get(20 << 20)
+	assert.Eq(t, 32<<20, cap(b.B))
+	a.Release()
+	b.Release()
+	runtime.GC()
+	runtime.GC()
+	if bp.Get(16<<20) != a {
+		t.Fatal("large buffer not retained")
+	}
+	s := bp.Stats()
+	last := s.Classes[len(s.Classes)-1]
+	assert.Eq(t, uint64(1), last.RejectedPuts)
+	assert.Eq(t, uint64(1), s.Classes[len(s.Classes)-2].Hits)
+	// Smaller classes keep using sync.Pool.
+	assert.Nil(t, bp.table.Load().retained[size2class(100)])
+	c := bp.Get(64 << 20)
+	assert.Eq(t, 64<<20, cap(c.B))
+}
+
+func TestLargeClassesInvalid(t *testing.T) {
+	_, err := NewWithClasses([]int{32, 1 << 20}, WithLargeClasses([]int{1 << 20}, Retention{MaxBytes: 1 << 30}))
+	assert.Err(t, err)
+	_, err = NewWithClasses([]int{32, 1 << 20}, WithLargeClasses([]int{4 << 20, 2 << 20}, Retention{MaxBytes: 1 << 30}))
+	assert.Err(t, err)
+	defer func() {
+		if recover() == nil {
+			t.Fatal("no panic")
+		}
+	}()
+	New(WithLargeClasses([]int{4 << 20}, Retention{MaxBuffers: 1}))
+}
@@ -0,0 +1,119 @@
+package bpool
+
+import (
+	"io"
+	"sync/atomic"
+)
+
+// Shared is a reference-counted Bytes for a payload that several goroutines
+// hold at once, such as a message fanned out to many connections. Each
+// holder calls Release when done; the last one returns the Bytes to its
+// Allocator. Holders must only read the Bytes.
+type Shared struct {
+	b    *Bytes
+	refs atomic.Int32
+}
+
+// NewShared wraps b with one reference, owned by the caller. b must not be
+// released directly afterwards.
+func NewShared(b *Bytes) *Shared {
+	s := &Shared{b: b}
+	s.refs.Store(1)
+	return s
+}
+
+// Bytes returns the shared Bytes. It is only valid while the caller holds a
+// reference.
+func (s *Shared) Bytes() *Bytes {
+	return s.b
+}
+
+// Retain adds a reference, to be handed to another holder, and returns s.
+// It panics if s was already released by its last holder.
+func (s *Shared) Retain() *Shared {
+	if s.refs.Add(1) <= 1 {
+		panic("bpool.Shared: Retain after the last Release")
+	}
+	return s
+}
+
+// Release drops a reference; dropping the last one releases the Bytes. It
+// panics if s has no references left, which means Release was called more
+// often than NewShared and Retain.
+func (s *Shared) Release() {
+	switch n := s.refs.Add(-1); {
+	case n == 0:
+		s.b.Release()
+	case n < 0:
+		panic("bpool.Shared: Release without a reference")
+	}
+}
+
+// Refs returns the current number of references.
+func (s *Shared) Refs() int {
+	return int(s.refs.Load())
+}
+
+// WriteTo writes the unread portion of the shared Bytes to w. Unlike
+// Bytes.DrainTo it does not consume anything and touches no state of the
+// Bytes, so every holder can write the whole payload, concurrently if need
+// be. It implements io.WriterTo.
+func (s *Shared) WriteTo(w io.Writer) (n int64, err error) {
+	p := s.b.Bytes()
+	m, err := w.Write(p)
+	if err == nil && m != len(p) {
+		err = io.ErrShortWrite
+	}
+	return int64(m), err
+}
+
+// Slice returns a view of the bytes [i:j) of the unread portion of the shared
+// Bytes, holding a reference of its own: the Bytes stays out of the pool until
+// the view is released as well. It panics if the bounds are out of range.
+func (s *Shared) Slice(i, j int) View {
+	return View{b: slice(s.b.Bytes(), i, j), parent: s.Retain()}
+}
+
+// View is a read-only window into a Shared, returned by Shared.Slice. It is
+// a small value that costs no allocation. Each View returned by Slice must be
+// released exactly once; copying it does not add a reference.
+type View struct {
+	b      []byte
+	parent *Shared
+}
+
+// Bytes returns the bytes of v. They are only valid until v is released and
+// must not be modified.
+func (v View) Bytes() []byte {
+	return v.b
+}
+
+// String returns a copy of the bytes of v as a string.
+func (v View) String() string {
+	return string(v.b)
+}
+
+// Len returns the number of bytes in v.
+func (v View) Len() int {
+	return len(v.b)
+}
+
+// Slice returns the view of the bytes [i:j) of v, holding a reference of its
+// own to the parent of v.
+func (v View) Slice(i, j int) View {
+	return View{b: slice(v.b, i, j), parent: v.parent.Retain()}
+}
+
+// Release drops the reference of v to its parent.
+func (v View) Release() {
+	v.parent.Release()
+}
+
+// slice returns p[i:j:j], checking j against the length rather than the
+// capacity of p.
+func slice(p []byte, i, j int) []byte {
+	if i < 0 || j < i || j > len(p) {
+		panic("bpool.Shared: slice bounds out of range")
+	}
+	return p[i:j:j]
+}
@@ -0,0 +1,112 @@
+package bpool
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestShared_FanOut(t *testing.T) {
+	bp := New(WithStats())
+	pb := bp.Get(64)
+	pb.WriteString("payload")
+	s := NewShared(pb)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(s *Shared) {
+			defer wg.Done()
+			defer s.Release()
+			assert.Eq(t, "payload", s.Bytes().String())
+		}(s.Retain())
+	}
+	s.Release()
+	wg.Wait()
+
+	assert.Eq(t, 0, s.Refs())
+	var puts uint64
+	for _, c := range bp.Stats().Classes {
+		puts += c.Puts
+	}
+	assert.Eq(t, uint64(1), puts)
+}
+
+func TestShared_WriteTo(t *testing.T) {
+	pb := Get(64)
+	pb.WriteString("header|payload")
+	pb.Next(7)
+	s := NewShared(pb)
+
+	var outs [2]bytes.Buffer
+	var wg sync.WaitGroup
+	for i := range outs {
+		wg.Add(1)
+		go func(s *Shared, w *bytes.Buffer) {
+			defer wg.Done()
+			defer s.Release()
+			n, err := s.WriteTo(w)
+			assert.NoErr(t, err)
+			assert.Eq(t, int64(7), n)
+		}(s.Retain(), &outs[i])
+	}
+	wg.Wait()
+	for i := range outs {
+		assert.Eq(t, "payload", outs[i].String())
+	}
+	assert.Eq(t, "payload", pb.String())
+	s.Release()
+}
+
+func TestShared_Misuse(t *testing.T) {
+	s := NewShared(Get(8))
+	s.Release()
+	assert.Panics(t, func() { s.Release() })
+	assert.Panics(t, func() { s.Retain() })
+}
+
+func TestShared_DebugUseAfterRelease(t *testing.T) {
+	bp := New(WithDebug())
+	s := NewShared(bp.Get(8))
+	s.Retain()
+	s.Release()
+	s.Bytes().WriteString("still held")
+	s.Release()
+	assert.Panics(t, func() { s.Bytes().WriteString("gone") })
+}
+
+func TestShared_Views(t *testing.T) {
+	ca := &countingAllocator{Allocator: New()}
+	pb := ca.Get(64)
+	pb.WriteString("GET /index.html HTTP/1.1")
+	pb.Next(4)
+	s := NewShared(pb)
+	path := s.Slice(0, 11)
+	proto := s.Slice(12, 20)
+	name := path.Slice(1, 6)
+	s.Release()
+	assert.Eq(t, 3, s.Refs())
+	assert.Eq(t, 0, ca.puts)
+
+	assert.Eq(t, "/index.html", path.String())
+	assert.Eq(t, "HTTP/1.1", string(proto.Bytes()))
+	assert.Eq(t, 5, name.Len())
+	path.Release()
+	proto.Release()
+	assert.Eq(t, "index", name.String())
+	assert.Eq(t, 0, ca.puts)
+	name.Release()
+	assert.Eq(t, 1, ca.puts)
+	assert.Panics(t, func() { name.Release() })
+}
+
+func TestShared_SliceOutOfRange(t *testing.T) {
+	pb := Get(8)
+	pb.WriteString("abc")
+	s := NewShared(pb)
+	assert.Panics(t, func() { s.Slice(2, 4) })
+	assert.Eq(t, 1, s.Refs())
+	s.Release()
+}
@@ -1,6 +1,20 @@
 package bpool
 
-import "math/bits"
+//go:generate go run ./cmd/sizeclassgen
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+)
+
+// _MaxClassSize is the largest class size accepted by NewWithClasses. It is
+// the largest int on 32-bit platforms.
+const _MaxClassSize = 1<<31 - 1
+
+// ErrInvalidClasses is returned by NewWithClasses when the class list can not
+// be turned into a size-class table.
+var ErrInvalidClasses = errors.New("bpool: invalid size classes")
 
 func size2class(size int) (sizeclass uint8) {
 	if size <= smallSizeMax-8 {
@@ -13,6 +27,197 @@ func size2class(size int) (sizeclass uint8) {
 	return
 }
 
+// sizeClasses is the runtime form of the tables in sizeclass_table.go. Like
+// size2class it looks sizes up in a table per size range, so a table built
+// from a custom class list is as cheap to query as the generated one.
+type sizeClasses struct {
+	classToSize []uint32
+	// Each lookup entry holds the first class that can serve the smallest
+	// size of its range; sizeToClass steps forward from there when a class
+	// boundary falls inside the range. sizeToClassBig has histBigSteps
+	// entries per power of two, see bigBin.
+	sizeToClass8   []uint8
+	sizeToClass128 []uint8
+	sizeToClassBig []uint8
+	// max8 is the largest size looked up in sizeToClass8. Custom tables use
+	// it up to _MaxSmallSize and have no sizeToClass128.
+	max8    int
+	minSize int
+	maxSize int
+	// exact is set when every lookup entry serves its whole range, so
+	// sizeToClass never has to step forward.
+	exact bool
+}
+
+var defaultClasses = newDefaultClasses()
+
+// newDefaultClasses wraps the generated tables. Its big-size lookup has the
+// layout of a custom table; every entry maps exactly since the big classes
+// are powers of two.
+func newDefaultClasses() *sizeClasses {
+	sc := &sizeClasses{
+		classToSize:    class_to_size[:],
+		sizeToClass8:   size_to_class8[:],
+		sizeToClass128: size_to_class128[:],
+		sizeToClassBig: make([]uint8, len(size_to_class_big)*histBigSteps),
+		max8:           smallSizeMax - 8,
+		minSize:        _MinByteSize,
+		maxSize:        _MaxBigSize,
+		exact:          true,
+	}
+	for i := range sc.sizeToClassBig {
+		sc.sizeToClassBig[i] = size_to_class_big[i/histBigSteps]
+	}
+	return sc
+}
+
+// maxLookupSteps is the most classes sizeToClass steps over past a lookup
+// entry, which keeps it O(1). An entry of a custom table covers 8 sizes up to
+// _MaxSmallSize, so it never needs more steps there; newSizeClasses rejects
+// class lists that need more in the big range.
+const maxLookupSteps = 7
+
+// newSizeClasses validates classes and builds the lookup tables for them.
+// classes must be strictly increasing positive sizes; class 0 is implicit.
+func newSizeClasses(classes []int) (sc *sizeClasses, err error) {
+	if len(classes) == 0 {
+		return nil, fmt.Errorf("%w: no classes given", ErrInvalidClasses)
+	}
+	if len(classes) > 255 {
+		return nil, fmt.Errorf("%w: %d classes, at most 255 are supported", ErrInvalidClasses, len(classes))
+	}
+	for i, v := range classes {
+		if v <= 0 || v > _MaxClassSize {
+			return nil, fmt.Errorf("%w: class size %d out of range (0, %d]", ErrInvalidClasses, v, _MaxClassSize)
+		}
+		if i > 0 && v <= classes[i-1] {
+			return nil, fmt.Errorf("%w: class sizes must be strictly increasing, got %d after %d",
+				ErrInvalidClasses, v, classes[i-1])
+		}
+	}
+	sc = &sizeClasses{
+		classToSize:  make([]uint32, len(classes)+1),
+		sizeToClass8: make([]uint8, _MaxSmallSize/smallSizeDiv+1),
+		max8:         _MaxSmallSize,
+		minSize:      classes[0],
+		maxSize:      classes[len(classes)-1],
+		exact:        true,
+	}
+	for i, v := range classes {
+		sc.classToSize[i+1] = uint32(v)
+	}
+	// fill sets the entry for the sizes (lo, hi] to the smallest class that
+	// can hold lo+1, and records whether hi needs a later one.
+	steps := 0
+	fill := func(entry *uint8, lo, hi int) {
+		*entry = sc.first(lo + 1)
+		n := int(sc.first(hi) - *entry)
+		sc.exact = sc.exact && n == 0
+		steps = max(steps, n)
+	}
+	for i := 1; i < len(sc.sizeToClass8); i++ {
+		fill(&sc.sizeToClass8[i], (i-1)*smallSizeDiv, i*smallSizeDiv)
+	}
+	if sc.maxSize > _MaxSmallSize {
+		sc.sizeToClassBig = make([]uint8, (bsr(sc.maxSize)-_MaxSamllSizePower)*histBigSteps)
+		for i := range sc.sizeToClassBig {
+			bit := i/histBigSteps + _MaxSamllSizePower + 1
+			width := 1 << (bit - 1 - histBigStepsBits)
+			lo := 1<<(bit-1) + i%histBigSteps*width
+			fill(&sc.sizeToClassBig[i], lo, lo+width)
+		}
+	}
+	if steps > maxLookupSteps {
+		return nil, fmt.Errorf("%w: %d classes share a lookup range above %d, at most %d are supported",
+			ErrInvalidClasses, steps+1, _MaxSmallSize, maxLookupSteps+1)
+	}
+	return
+}
+
+// first returns the smallest class whose size is at least size. Sizes beyond
+// the last class map to the last class; Get never looks them up.
+func (sc *sizeClasses) first(size int) uint8 {
+	for c := 1; c < len(sc.classToSize); c++ {
+		if int(sc.classToSize[c]) >= size {
+			return uint8(c)
+		}
+	}
+	return uint8(len(sc.classToSize) - 1)
+}
+
+// sizeToClass returns the smallest class whose size is at least size.
+// size must be in [0, sc.maxSize].
+func (sc *sizeClasses) sizeToClass(size int) (class uint8) {
+	if size <= sc.max8 {
+		class = sc.sizeToClass8[divRoundUp(uintptr(size), smallSizeDiv)]
+	} else if size <= _MaxSmallSize {
+		class = sc.sizeToClass128[divRoundUp(uintptr(size)-smallSizeMax, largeSizeDiv)]
+	} else {
+		class = sc.sizeToClassBig[bigBin(size)]
+	}
+	if !sc.exact {
+		class = sc.stepUp(class, size)
+	}
+	return
+}
+
+// stepUp returns the first class from class on that can hold size. It
+// steps over at most maxLookupSteps classes.
+func (sc *sizeClasses) stepUp(class uint8, size int) uint8 {
+	for int(sc.classToSize[class]) < size {
+		class++
+	}
+	return class
+}
+
+// bigBin returns the index of the big-size lookup entry, or histogram bin,
+// of a size above _MaxSmallSize: each power of two is split into
+// histBigSteps of them.
+func bigBin(size int) int {
+	// size is in (1<<(bit-1), 1<<bit].
+	bit := bsr(size)
+	step := divRoundUp(uintptr(size-1<<(bit-1)), 1<<(bit-1-histBigStepsBits))
+	return (bit-_MaxSamllSizePower-1)*histBigSteps + int(step) - 1
+}
+
+// putClass returns the class a buffer with the given capacity is stored in,
+// or false if the capacity is outside the class range. A capacity between
+// two classes is demoted to the lower one.
+func (sc *sizeClasses) putClass(capacity int) (class uint8, ok bool) {
+	if capacity > sc.maxSize || capacity < sc.minSize {
+		return
+	}
+	class = sc.sizeToClass(capacity)
+	if capacity < int(sc.classToSize[class]) {
+		// class cant less one
+		// because capacity >= sc.minSize
+		class = class - 1
+	}
+	return class, true
+}
+
+// FineClasses returns the class sizes of the built-in table with every
+// power-of-two step between 32 KB and 8 MB split into four classes, for use
+// with NewWithClasses. It trades a few more classes for less internal
+// fragmentation of big buffers: a 65 KB request gets an 80 KB buffer
+// instead of a 128 KB one.
+func FineClasses() (classes []int) {
+	classes = make([]int, len(fine_class_to_size)-1)
+	for i := range classes {
+		classes[i] = int(fine_class_to_size[i+1])
+	}
+	return
+}
+
+// classes returns the class sizes, without the implicit class 0.
+func (sc *sizeClasses) classes() (sizes []int) {
+	sizes = make([]int, len(sc.classToSize)-1)
+	for i := range sizes {
+		sizes[i] = int(sc.classToSize[i+1])
+	}
+	return
+}
+
 // divRoundUp returns ceil(n / a).
 func divRoundUp(n, a uintptr) uintptr {
 	// a is generally a power of two. This will get inlined and
@@ -1,3 +1,5 @@
+// Code generated by sizeclassgen; DO NOT EDIT.
+
 package bpool
 
 const (
@@ -15,6 +17,12 @@ const (
 //goland:noinspection GoSnakeCaseUsage
 var class_to_size = [...]uint32{0, 32, 64, 96, 128, 160, 224, 256, 320, 384, 448, 512, 640, 768, 1024, 1280, 1536, 1792, 2048, 2304, 2688, 3072, 3456, 4096, 4864, 5376, 6144, 6784, 8192, 9472, 10240, 10880, 12288, 13568, 14336, 16384, 18432, 19072, 20480, 21760, 24576, 27264, 28672, 32768, 65536, 131072, 262144, 524288, 1048576, 2097152, 4194304, 8388608}
 
+// fine_class_to_size splits every power-of-two step above _MaxSmallSize
+// into 4 classes, see FineClasses.
+//
+//goland:noinspection GoSnakeCaseUsage
+var fine_class_to_size = [...]uint32{0, 32, 64, 96, 128, 160, 224, 256, 320, 384, 448, 512, 640, 768, 1024, 1280, 1536, 1792, 2048, 2304, 2688, 3072, 3456, 4096, 4864, 5376, 6144, 6784, 8192, 9472, 10240, 10880, 12288, 13568, 14336, 16384, 18432, 19072, 20480, 21760, 24576, 27264, 28672, 32768, 40960, 49152, 57344, 65536, 81920, 98304, 114688, 131072, 163840, 196608, 229376, 262144, 327680, 393216, 458752, 524288, 655360, 786432, 917504, 1048576, 1310720, 1572864, 1835008, 2097152, 2621440, 3145728, 3670016, 4194304, 5242880, 6291456, 7340032, 8388608}
+
 //goland:noinspection GoSnakeCaseUsage
 var size_to_class8 = [smallSizeMax / smallSizeDiv]uint8{0, 1, 1, 1, 1, 2, 2, 2, 2, 3, 3, 3, 3, 4, 4, 4, 4, 5, 5, 5, 5, 6, 6, 6, 6, 6, 6, 6, 6, 7, 7, 7, 7, 8, 8, 8, 8, 8, 8, 8, 8, 9, 9, 9, 9, 9, 9, 9, 9, 10, 10, 10, 10, 10, 10, 10, 10, 11, 11, 11, 11, 11, 11, 11, 11, 12, 12, 12, 12, 12, 12, 12, 12, 12, 12, 12, 12, 12, 12, 12, 12, 13, 13, 13, 13, 13, 13, 13, 13, 13, 13, 13, 13, 13, 13, 13, 13, 14, 14, 14, 14, 14, 14, 14, 14, 14, 14, 14, 14, 14, 14, 14, 14, 14, 14, 14, 14, 14, 14, 14, 14, 14, 14, 14, 14, 14, 14, 14}
 
@@ -0,0 +1,71 @@
+package bpool
+
+import "sync/atomic"
+
+// ClassStats is a snapshot of the counters of one size class.
+type ClassStats struct {
+	// Size is the capacity of the buffers in the class.
+	Size int
+	// Hits counts Get calls served by a cached buffer.
+	Hits uint64
+	// Misses counts Get calls that had to allocate a new buffer.
+	Misses uint64
+	// Puts counts buffers accepted into the class.
+	Puts uint64
+	// RejectedPuts counts buffers dropped by Put although their capacity
+	// falls into the class range, because they exceed a retention limit.
+	RejectedPuts uint64
+}
+
+// Stats is a snapshot of the counters of a BytesPool.
+type Stats struct {
+	// Classes holds one entry per size class, smallest class first.
+	Classes []ClassStats
+	// Oversized counts Get calls larger than the largest class, which are
+	// always allocated directly.
+	Oversized uint64
+	// RejectedPuts counts buffers dropped by Put because their capacity is
+	// smaller than the first class or larger than the last one.
+	RejectedPuts uint64
+}
+
+// classCounters is padded to a cache line so that goroutines working on
+// different classes do not contend on the same line.
+type classCounters struct {
+	hits         atomic.Uint64
+	misses       atomic.Uint64
+	puts         atomic.Uint64
+	rejectedPuts atomic.Uint64
+	_            [32]byte
+}
+
+type poolCounters struct {
+	oversized    atomic.Uint64
+	rejectedPuts atomic.Uint64
+	_            [48]byte
+}
+
+// Stats returns a snapshot of the per-class counters of bp. The counters are
+// read one by one, so a snapshot taken under load is not atomic as a whole.
+// Unless bp was created with WithStats all counters are zero.
+func (bp *BytesPool) Stats() (s Stats) {
+	t := bp.table.Load()
+	s.Classes = make([]ClassStats, len(t.classes.classToSize)-1)
+	for i := range s.Classes {
+		s.Classes[i].Size = int(t.classes.classToSize[i+1])
+		if t.counters == nil {
+			continue
+		}
+		c := &t.counters[i+1]
+		s.Classes[i] = ClassStats{
+			Size:         s.Classes[i].Size,
+			Hits:         c.hits.Load(),
+			Misses:       c.misses.Load(),
+			Puts:         c.puts.Load(),
+			RejectedPuts: c.rejectedPuts.Load(),
+		}
+	}
+	s.Oversized = bp.poolCounters.oversized.Load()
+	s.RejectedPuts = bp.poolCounters.rejectedPuts.Load()
+	return
+}